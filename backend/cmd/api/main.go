@@ -2,11 +2,11 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
 	"stockapi/internal/application"
 	"stockapi/internal/infrastructure/api"
@@ -14,7 +14,9 @@ import (
 	"stockapi/internal/infrastructure/external/stockapi"
 	"stockapi/internal/infrastructure/logging"
 	"stockapi/internal/infrastructure/persistence/cockroach"
+	"stockapi/internal/domain/analysis"
 	"stockapi/internal/domain/shared"
+	"stockapi/internal/domain/stock"
 )
 
 func main() {
@@ -37,39 +39,126 @@ func main() {
 
 	domainLogger := shared.NewDomainLogger(logger)
 
+	// Open the database and bring the schema up to date
+	dbPool, err := cockroach.NewPool(ctx, cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("error connecting to database: %v", err)
+	}
+	if err := cockroach.Migrate(ctx, dbPool); err != nil {
+		log.Fatalf("error migrating database: %v", err)
+	}
+
 	// Initialize repositories and clients with logger
-	stockRepo, err := cockroach.NewStockRepository(ctx, cfg.DatabaseURL, logger)
+	stockRepo := cockroach.NewStockRepository(dbPool, logger)
+	actionRepo := cockroach.NewRatingActionRepository(dbPool, logger)
+	searchRepo := cockroach.NewSearchRepository(dbPool, logger)
+	correctionRepo := cockroach.NewCorrectionRepository(dbPool, logger)
+	syncStateRepo := cockroach.NewSyncStateRepository(dbPool, logger)
+	syncRunRepo := cockroach.NewSyncRunRepository(dbPool, logger)
+	rejectedRepo := cockroach.NewRejectedRecordRepository(dbPool, logger)
+	syncLeaseRepo := cockroach.NewSyncLeaseRepository(dbPool, logger)
+	versionRepo := cockroach.NewDataVersionRepository(dbPool, logger)
+	snapshotRepo := cockroach.NewAnalysisSnapshotRepository(dbPool, logger)
+	historyRepo := cockroach.NewScoreHistoryRepository(dbPool, logger)
+	companyRepo := cockroach.NewClassificationRepository(dbPool, logger)
+	portfolioRepo := cockroach.NewPortfolioRepository(dbPool, logger)
+	apiClient, err := newStockAPIPort(cfg, logger)
 	if err != nil {
-		log.Fatalf("error initializing stock repository: %v", err)
+		log.Fatalf("error initializing stock provider: %v", err)
 	}
-	apiClient := stockapi.NewStockAPIClient(cfg.ExternalAPIURL, cfg.AuthToken, logger)
 
 	// Initialize application with WebSocket handler
-	app := application.NewStockApplication(stockRepo, apiClient, domainLogger)
+	app := application.NewStockApplication(application.Dependencies{
+		StockRepo:      stockRepo,
+		ActionRepo:     actionRepo,
+		SearchRepo:     searchRepo,
+		CorrectionRepo: correctionRepo,
+		SyncStateRepo:  syncStateRepo,
+		SyncRunRepo:    syncRunRepo,
+		RejectedRepo:   rejectedRepo,
+		SyncLeaseRepo:  syncLeaseRepo,
+		VersionRepo:    versionRepo,
+		SnapshotRepo:   snapshotRepo,
+		HistoryRepo:    historyRepo,
+		CompanyRepo:    companyRepo,
+		PortfolioRepo:  portfolioRepo,
+		StockAPI:       apiClient,
+		RecordParser:   stockapi.NewRecordParser(),
+		Logger:         domainLogger,
+	}, application.Settings{
+		FullSyncInterval:      cfg.FullSyncInterval,
+		SyncLeaseTTL:          cfg.SyncLeaseTTL,
+		InstanceID:            cfg.InstanceID,
+		AnalysisCacheTTL:      cfg.AnalysisCacheTTL,
+		AnalysisSnapshots:     cfg.AnalysisSnapshots,
+		ScoreHistoryRetention: cfg.ScoreHistoryRetention,
+		ScoreHistoryInterval:  cfg.ScoreHistoryInterval,
+		Analysis: analysis.Options{
+			StalenessWindow: cfg.StalenessWindow,
+			HalfLife:        cfg.RatingHalfLife,
+		},
+	})
 
 	// Initialize and run server
-	server := api.NewServer(cfg, app)
+	server, err := api.NewServer(cfg, app)
+	if err != nil {
+		log.Fatalf("error initializing server: %v", err)
+	}
 
 	// Run server in a goroutine
+	serverErr := make(chan error, 1)
 	go func() {
-		if err := server.Run(); err != nil {
-			log.Printf("error in server: %v", err)
-			cancel()
-		}
+		serverErr <- server.Run()
 	}()
 
-	// Wait for shutdown signal
-	<-sigChan
+	// Wait for shutdown signal or server failure
+	select {
+	case <-sigChan:
+	case err := <-serverErr:
+		if err != nil {
+			log.Printf("error in server: %v", err)
+		}
+	}
 	log.Println("starting graceful shutdown...")
 
 	// Create context with timeout for shutdown
-	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 	defer shutdownCancel()
 
-	// Perform cleanup and shutdown
+	// Stop accepting connections and drain in-flight requests
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("error draining requests: %v", err)
+	}
+
+	// Cancel and await running sync jobs
+	if err := app.Shutdown(shutdownCtx); err != nil {
+		log.Printf("error stopping running jobs: %v", err)
+	}
+
+	// Release the database only once nothing else uses it
 	if err := stockRepo.Close(shutdownCtx); err != nil {
 		log.Printf("error during shutdown: %v", err)
 	}
 
 	log.Println("server stopped correctly")
 }
+
+// newStockAPIPort builds the configured providers behind a composite that
+// merges their results and attributes each record to its source
+func newStockAPIPort(cfg *config.Config, logger shared.Logger) (stock.StockAPIPort, error) {
+	providers := make([]stockapi.NamedProvider, 0, len(cfg.Providers))
+	for _, provider := range cfg.Providers {
+		var port stock.StockAPIPort
+		switch provider.Type {
+		case config.ProviderFile:
+			var err error
+			if port, err = stockapi.NewFileStockProvider(provider.Path, logger); err != nil {
+				return nil, fmt.Errorf("provider %s: %w", provider.Name, err)
+			}
+		default:
+			port = stockapi.NewStockAPIClient(provider.URL, provider.AuthToken, logger)
+		}
+		providers = append(providers, stockapi.NamedProvider{Name: provider.Name, Port: port})
+	}
+	return stockapi.NewCompositeStockProvider(providers, logger), nil
+}
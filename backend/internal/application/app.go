@@ -1,25 +1,140 @@
 package application
 
 import (
+	"context"
+	"errors"
 	"stockapi/internal/application/services"
 	"stockapi/internal/domain/analysis"
+	"stockapi/internal/domain/portfolio"
 	"stockapi/internal/domain/shared"
 	"stockapi/internal/domain/stock"
+	"time"
 )
 
 type StockApplication struct {
-	StockService    *services.StockService
-	AnalysisService *services.AnalysisApplicationService
+	StockService          *services.StockService
+	AnalysisService       *services.AnalysisApplicationService
+	HealthService         *services.HealthService
+	QuarantineService     *services.QuarantineService
+	ClassificationService *services.ClassificationService
+	PortfolioService      *services.PortfolioService
+	AnomalyService        *services.AnomalyService
+	ComparisonService     *services.ComparisonService
+	SearchService         *services.SearchService
+	CorrectionService     *services.CorrectionService
 }
 
-func NewStockApplication(
-	stockRepo stock.Repository,
-	stockAPI stock.StockAPIPort,
-	logger *shared.DomainLogger,
-) *StockApplication {
-	analysisService := analysis.NewAnalysisService(stockRepo, logger)
+// Dependencies are the ports the application is built on
+type Dependencies struct {
+	StockRepo      stock.Repository
+	ActionRepo     stock.RatingActionRepository
+	SearchRepo     stock.SearchRepository
+	CorrectionRepo stock.CorrectionRepository
+	SyncStateRepo  stock.SyncStateRepository
+	SyncRunRepo    stock.SyncRunRepository
+	RejectedRepo   stock.RejectedRecordRepository
+	SyncLeaseRepo  stock.SyncLeaseRepository
+	VersionRepo    stock.DataVersionRepository
+	SnapshotRepo   analysis.SnapshotRepository
+	HistoryRepo    analysis.ScoreHistoryRepository
+	CompanyRepo    stock.ClassificationRepository
+	PortfolioRepo  portfolio.Repository
+	StockAPI       stock.StockAPIPort
+	RecordParser   stock.RecordParser
+	Logger         *shared.DomainLogger
+}
+
+// Settings tune the behaviour of the application services
+type Settings struct {
+	// FullSyncInterval forces a full provider crawl when the last one is
+	// older than it; zero disables the periodic full crawl
+	FullSyncInterval time.Duration
+
+	// SyncLeaseTTL bounds how long a crashed replica blocks other syncs
+	SyncLeaseTTL time.Duration
+
+	// InstanceID names this replica in the sync lease
+	InstanceID string
+
+	// AnalysisCacheTTL bounds how long computed analyses are reused; zero
+	// disables the cache
+	AnalysisCacheTTL time.Duration
+
+	// AnalysisSnapshots shares computed analyses between replicas and
+	// restarts through the snapshot repository
+	AnalysisSnapshots bool
+
+	// ScoreHistoryRetention is how long recorded scores are kept; zero keeps
+	// them forever
+	ScoreHistoryRetention time.Duration
+
+	// ScoreHistoryInterval is how often the default scores are recorded in
+	// the history; zero disables recording
+	ScoreHistoryInterval time.Duration
+
+	// Analysis holds the staleness window and rating half-life used unless a
+	// request overrides them
+	Analysis analysis.Options
+}
+
+func NewStockApplication(deps Dependencies, settings Settings) *StockApplication {
+	analysisService := analysis.NewAnalysisService(deps.StockRepo, deps.Logger, settings.Analysis)
+	var snapshots analysis.SnapshotRepository
+	if settings.AnalysisSnapshots {
+		snapshots = deps.SnapshotRepo
+	}
+	stockService := services.NewStockService(
+		deps.StockRepo,
+		deps.ActionRepo,
+		deps.CorrectionRepo,
+		deps.SyncStateRepo,
+		deps.SyncRunRepo,
+		deps.RejectedRepo,
+		deps.SyncLeaseRepo,
+		deps.VersionRepo,
+		deps.StockAPI,
+		deps.Logger,
+		services.SyncSettings{
+			FullSyncInterval: settings.FullSyncInterval,
+			LeaseTTL:         settings.SyncLeaseTTL,
+			InstanceID:       settings.InstanceID,
+		},
+	)
+	analysisApplication := services.NewAnalysisApplicationService(
+		analysisService,
+		deps.VersionRepo,
+		snapshots,
+		deps.HistoryRepo,
+		deps.Logger,
+		settings.AnalysisCacheTTL,
+		settings.ScoreHistoryRetention,
+	)
+	if settings.ScoreHistoryInterval > 0 {
+		analysisApplication.StartScoreHistory(settings.ScoreHistoryInterval)
+	}
 	return &StockApplication{
-		StockService:    services.NewStockService(stockRepo, stockAPI, logger),
-		AnalysisService: services.NewAnalysisApplicationService(analysisService),
+		StockService:    stockService,
+		AnalysisService: analysisApplication,
+		HealthService:   services.NewHealthService(deps.StockRepo, deps.StockAPI, stockService),
+		QuarantineService: services.NewQuarantineService(
+			deps.RejectedRepo,
+			deps.StockRepo,
+			deps.ActionRepo,
+			deps.CorrectionRepo,
+			deps.VersionRepo,
+			deps.RecordParser,
+			deps.Logger,
+		),
+		ClassificationService: services.NewClassificationService(deps.CompanyRepo, deps.VersionRepo, deps.Logger),
+		PortfolioService:      services.NewPortfolioService(analysisApplication, deps.PortfolioRepo, deps.ActionRepo, deps.Logger),
+		AnomalyService:        services.NewAnomalyService(deps.ActionRepo, deps.VersionRepo, analysis.DefaultAnomalyOptions(), deps.Logger),
+		ComparisonService:     services.NewComparisonService(analysisApplication, deps.ActionRepo, deps.Logger),
+		SearchService:         services.NewSearchService(deps.SearchRepo, deps.Logger),
+		CorrectionService:     services.NewCorrectionService(deps.StockRepo, deps.CorrectionRepo, deps.VersionRepo, deps.Logger),
 	}
 }
+
+// Shutdown cancels and awaits background work owned by the application services
+func (a *StockApplication) Shutdown(ctx context.Context) error {
+	return errors.Join(a.StockService.Shutdown(ctx), a.AnalysisService.Shutdown(ctx))
+}
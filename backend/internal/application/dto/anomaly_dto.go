@@ -0,0 +1,92 @@
+package dto
+
+import (
+	"stockapi/internal/domain/analysis"
+	"stockapi/internal/domain/stock"
+	"time"
+)
+
+type AnomalyBaselineResponse struct {
+	Name   string  `json:"name"`
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"std_dev"`
+}
+
+type AnomalyResponse struct {
+	Kind      string                 `json:"kind"`
+	Ticker    string                 `json:"ticker"`
+	Brokerage string                 `json:"brokerage,omitempty"`
+	Time      time.Time              `json:"time"`
+	Actions   []BrokerRatingResponse `json:"actions"`
+
+	// Set on target outliers
+	TargetChange *float64                 `json:"target_change,omitempty"`
+	Baseline     *AnomalyBaselineResponse `json:"baseline,omitempty"`
+	ZScore       *float64                 `json:"z_score,omitempty"`
+
+	// Set on rating jumps
+	Levels *int `json:"levels,omitempty"`
+}
+
+type AnomaliesResponse struct {
+	Since     time.Time         `json:"since"`
+	Anomalies []AnomalyResponse `json:"anomalies"`
+	Count     int               `json:"count"`
+}
+
+// ToActionResponses describes rating actions the way broker ratings are
+func ToActionResponses(actions []*stock.Stock) []BrokerRatingResponse {
+	responses := make([]BrokerRatingResponse, len(actions))
+	for i, a := range actions {
+		responses[i] = BrokerRatingResponse{
+			Brokerage:  a.Brokerage,
+			Tier:       analysis.TierOf(a.Brokerage).String(),
+			Action:     a.Action,
+			RatingFrom: string(a.Rating.From),
+			RatingTo:   string(a.Rating.To),
+			TargetFrom: a.Target.From.Amount,
+			TargetTo:   a.Target.To.Amount,
+			Time:       a.Time,
+		}
+		if a.Target.From.Amount > 0 {
+			responses[i].TargetChange = a.Target.To.Amount/a.Target.From.Amount - 1
+		}
+	}
+	return responses
+}
+
+func ToAnomalyResponse(anomaly analysis.Anomaly) AnomalyResponse {
+	response := AnomalyResponse{
+		Kind:      string(anomaly.Kind),
+		Ticker:    anomaly.Ticker.String(),
+		Brokerage: anomaly.Brokerage,
+		Time:      anomaly.Time,
+		Actions:   ToActionResponses(anomaly.Actions),
+	}
+
+	switch anomaly.Kind {
+	case analysis.AnomalyTargetOutlier:
+		response.TargetChange = &anomaly.TargetChange
+		response.ZScore = &anomaly.ZScore
+		response.Baseline = &AnomalyBaselineResponse{
+			Name:   anomaly.Baseline,
+			Mean:   anomaly.BaselineMean,
+			StdDev: anomaly.BaselineStdDev,
+		}
+	case analysis.AnomalyRatingJump:
+		response.Levels = &anomaly.Levels
+	}
+	return response
+}
+
+func ToAnomaliesResponse(since time.Time, anomalies []analysis.Anomaly) AnomaliesResponse {
+	responses := make([]AnomalyResponse, len(anomalies))
+	for i, anomaly := range anomalies {
+		responses[i] = ToAnomalyResponse(anomaly)
+	}
+	return AnomaliesResponse{
+		Since:     since,
+		Anomalies: responses,
+		Count:     len(responses),
+	}
+}
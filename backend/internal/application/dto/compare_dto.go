@@ -0,0 +1,67 @@
+package dto
+
+import (
+	"stockapi/internal/application/services"
+	"stockapi/internal/domain/analysis"
+	"time"
+)
+
+type ConsensusResponse struct {
+	Brokers       int     `json:"brokers"`
+	Positive      int     `json:"positive"`
+	Neutral       int     `json:"neutral"`
+	Negative      int     `json:"negative"`
+	AverageTarget float64 `json:"average_target"`
+}
+
+type TickerComparisonResponse struct {
+	Ticker string `json:"ticker"`
+	ScoreExplanationResponse
+	Ratings   []BrokerRatingResponse `json:"ratings"`
+	Consensus ConsensusResponse      `json:"consensus"`
+	// History follows the timeline of the comparison, null before the first
+	// recorded score. Scores are recorded with the default options only.
+	History []*float64 `json:"history"`
+}
+
+type ComparisonResponse struct {
+	From     time.Time                  `json:"from"`
+	To       time.Time                  `json:"to"`
+	Timeline []string                   `json:"timeline"`
+	Tickers  []TickerComparisonResponse `json:"tickers"`
+}
+
+func ToConsensusResponse(consensus analysis.Consensus) ConsensusResponse {
+	return ConsensusResponse{
+		Brokers:       consensus.Brokers,
+		Positive:      consensus.Positive,
+		Neutral:       consensus.Neutral,
+		Negative:      consensus.Negative,
+		AverageTarget: consensus.AverageTarget,
+	}
+}
+
+func ToComparisonResponse(comparison *services.Comparison) ComparisonResponse {
+	timeline := make([]string, len(comparison.Days))
+	for i, day := range comparison.Days {
+		timeline[i] = day.Format(time.DateOnly)
+	}
+
+	tickers := make([]TickerComparisonResponse, len(comparison.Tickers))
+	for i, column := range comparison.Tickers {
+		tickers[i] = TickerComparisonResponse{
+			Ticker:                   column.Ticker.String(),
+			ScoreExplanationResponse: ToScoreExplanationResponse(column.Explanation),
+			Ratings:                  ToActionResponses(column.Ratings),
+			Consensus:                ToConsensusResponse(column.Consensus),
+			History:                  column.History,
+		}
+	}
+
+	return ComparisonResponse{
+		From:     comparison.From,
+		To:       comparison.To,
+		Timeline: timeline,
+		Tickers:  tickers,
+	}
+}
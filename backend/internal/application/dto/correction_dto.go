@@ -0,0 +1,96 @@
+package dto
+
+import (
+	"stockapi/internal/application/services"
+	"stockapi/internal/domain/stock"
+	"time"
+)
+
+// StockEditRequest is a manual correction of a stock record. Omitted fields
+// keep their value on PATCH and are left empty on PUT.
+type StockEditRequest struct {
+	Ticker *string `json:"ticker,omitempty"`
+	StockFieldsRequest
+}
+
+type StockLockResponse struct {
+	Ticker   string    `json:"ticker"`
+	LockedBy string    `json:"locked_by"`
+	LockedAt time.Time `json:"locked_at"`
+}
+
+type CorrectionResponse struct {
+	ID     string         `json:"id"`
+	Action string         `json:"action"`
+	Admin  string         `json:"admin"`
+	At     time.Time      `json:"at"`
+	Before *StockResponse `json:"before,omitempty"`
+	After  *StockResponse `json:"after,omitempty"`
+}
+
+type CorrectionHistoryResponse struct {
+	StockID     string               `json:"stock_id"`
+	Locked      bool                 `json:"locked"`
+	Locks       []StockLockResponse  `json:"locks"`
+	Corrections []CorrectionResponse `json:"corrections"`
+	Count       int                  `json:"count"`
+}
+
+type ReleaseResponse struct {
+	StockID string `json:"stock_id"`
+	// Stock is omitted when the record was deleted
+	Stock *StockResponse `json:"stock,omitempty"`
+}
+
+func (r StockEditRequest) ToStockEdit() services.StockEdit {
+	return services.StockEdit{
+		Ticker: r.Ticker,
+		Edit:   r.ToEdit(),
+	}
+}
+
+func toOptionalStockResponse(s *stock.Stock) *StockResponse {
+	if s == nil {
+		return nil
+	}
+	response := ToStockResponse(s)
+	return &response
+}
+
+func ToCorrectionHistoryResponse(history *services.CorrectionHistory) CorrectionHistoryResponse {
+	locks := make([]StockLockResponse, len(history.Locks))
+	for i, lock := range history.Locks {
+		locks[i] = StockLockResponse{
+			Ticker:   lock.Ticker.String(),
+			LockedBy: lock.LockedBy,
+			LockedAt: lock.LockedAt,
+		}
+	}
+
+	corrections := make([]CorrectionResponse, len(history.Corrections))
+	for i, c := range history.Corrections {
+		corrections[i] = CorrectionResponse{
+			ID:     c.ID.String(),
+			Action: string(c.Action),
+			Admin:  c.Admin,
+			At:     c.At,
+			Before: toOptionalStockResponse(c.Before),
+			After:  toOptionalStockResponse(c.After),
+		}
+	}
+
+	return CorrectionHistoryResponse{
+		StockID:     history.StockID.String(),
+		Locked:      len(locks) > 0,
+		Locks:       locks,
+		Corrections: corrections,
+		Count:       len(corrections),
+	}
+}
+
+func ToReleaseResponse(stockID string, released *stock.Stock) ReleaseResponse {
+	return ReleaseResponse{
+		StockID: stockID,
+		Stock:   toOptionalStockResponse(released),
+	}
+}
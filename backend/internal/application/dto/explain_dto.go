@@ -0,0 +1,67 @@
+package dto
+
+import (
+	"stockapi/internal/domain/analysis"
+	"stockapi/internal/domain/stock"
+)
+
+type ScoreFactorResponse struct {
+	Name         string  `json:"name"`
+	Input        string  `json:"input"`
+	RawValue     float64 `json:"raw_value"`
+	Weight       float64 `json:"weight"`
+	Contribution float64 `json:"contribution"`
+	Decay        float64 `json:"decay"`
+}
+
+type ExclusionResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type ScoreExplanationResponse struct {
+	Stock          StockResponse         `json:"stock"`
+	Score          float64               `json:"score"`
+	RawScore       float64               `json:"raw_score"`
+	Clamped        bool                  `json:"clamped"`
+	Recommendation string                `json:"recommendation"`
+	Factors        []ScoreFactorResponse `json:"factors"`
+	Indicators     map[string]float64    `json:"indicators,omitempty"`
+	Excluded       *ExclusionResponse    `json:"excluded,omitempty"`
+}
+
+func ToScoreExplanationResponse(explanation *analysis.StockExplanation) ScoreExplanationResponse {
+	factors := make([]ScoreFactorResponse, len(explanation.Score.Factors))
+	for i, factor := range explanation.Score.Factors {
+		factors[i] = ScoreFactorResponse{
+			Name:         factor.Name,
+			Input:        factor.Input,
+			RawValue:     factor.RawValue,
+			Weight:       factor.Weight,
+			Contribution: factor.Contribution,
+			Decay:        factor.Decay,
+		}
+	}
+
+	response := ScoreExplanationResponse{
+		Stock:          ToStockResponse(explanation.Stock),
+		Score:          explanation.Score.Score,
+		RawScore:       explanation.Score.RawScore,
+		Clamped:        explanation.Score.Clamped,
+		Recommendation: explanation.Recommendation,
+		Factors:        factors,
+		Indicators:     explanation.Indicators,
+	}
+	if explanation.Excluded != nil {
+		exclusion := ToExclusionResponse(explanation.Excluded)
+		response.Excluded = &exclusion
+	}
+	return response
+}
+
+func ToExclusionResponse(reason *stock.DomainError) ExclusionResponse {
+	return ExclusionResponse{
+		Code:    reason.Code,
+		Message: reason.Message,
+	}
+}
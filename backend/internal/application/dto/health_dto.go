@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"stockapi/internal/application/services"
+	"time"
+)
+
+type CheckResponse struct {
+	Status  string                 `json:"status"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+type ReadinessResponse struct {
+	Status    string                   `json:"status"`
+	Checks    map[string]CheckResponse `json:"checks"`
+	CheckedAt time.Time                `json:"checked_at"`
+}
+
+func ToReadinessResponse(report services.ReadinessReport) ReadinessResponse {
+	checks := make(map[string]CheckResponse, len(report.Checks))
+	for name, check := range report.Checks {
+		checks[name] = CheckResponse{
+			Status:  check.Status,
+			Details: check.Details,
+		}
+	}
+	return ReadinessResponse{
+		Status:    report.Status,
+		Checks:    checks,
+		CheckedAt: report.CheckedAt,
+	}
+}
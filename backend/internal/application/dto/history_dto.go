@@ -0,0 +1,62 @@
+package dto
+
+import (
+	"stockapi/internal/application/services"
+	"time"
+)
+
+type ScorePointResponse struct {
+	AnalyzedAt     time.Time          `json:"analyzed_at"`
+	Score          float64            `json:"score"`
+	Recommendation string             `json:"recommendation"`
+	Indicators     map[string]float64 `json:"indicators"`
+}
+
+type RecommendationFlipResponse struct {
+	At        time.Time `json:"at"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Direction string    `json:"direction"`
+}
+
+type ScoreHistoryResponse struct {
+	Ticker string                       `json:"ticker"`
+	From   time.Time                    `json:"from"`
+	To     time.Time                    `json:"to"`
+	Points []ScorePointResponse         `json:"points"`
+	Flips  []RecommendationFlipResponse `json:"flips"`
+}
+
+func ToScoreHistoryResponse(history *services.ScoreHistory) ScoreHistoryResponse {
+	points := make([]ScorePointResponse, len(history.Points))
+	for i, point := range history.Points {
+		points[i] = ScorePointResponse{
+			AnalyzedAt:     point.AnalyzedAt,
+			Score:          point.Score,
+			Recommendation: point.Recommendation,
+			Indicators:     point.Indicators,
+		}
+	}
+
+	flips := make([]RecommendationFlipResponse, len(history.Flips))
+	for i, flip := range history.Flips {
+		direction := "downgrade"
+		if flip.Direction > 0 {
+			direction = "upgrade"
+		}
+		flips[i] = RecommendationFlipResponse{
+			At:        flip.At,
+			From:      flip.From,
+			To:        flip.To,
+			Direction: direction,
+		}
+	}
+
+	return ScoreHistoryResponse{
+		Ticker: history.Ticker.String(),
+		From:   history.From,
+		To:     history.To,
+		Points: points,
+		Flips:  flips,
+	}
+}
@@ -0,0 +1,146 @@
+package dto
+
+import (
+	"stockapi/internal/domain/portfolio"
+	"stockapi/internal/domain/stock"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ConstructPortfolioRequest holds the constraints of a new portfolio; zero
+// limits are not enforced
+type ConstructPortfolioRequest struct {
+	Name            string  `json:"name"`
+	MaxNames        int     `json:"max_names"`
+	MaxWeight       float64 `json:"max_weight"`
+	MaxSectorWeight float64 `json:"max_sector_weight"`
+	MinScore        float64 `json:"min_score"`
+	Weighting       string  `json:"weighting"`
+}
+
+// UploadHoldingsRequest holds the positions actually held
+type UploadHoldingsRequest struct {
+	Name     string               `json:"name"`
+	Holdings []HoldingUploadEntry `json:"holdings"`
+}
+
+type HoldingUploadEntry struct {
+	Ticker    string  `json:"ticker"`
+	Quantity  float64 `json:"quantity"`
+	CostBasis float64 `json:"cost_basis"`
+}
+
+type PortfolioConstraintsResponse struct {
+	MaxNames        int     `json:"max_names"`
+	MaxWeight       float64 `json:"max_weight"`
+	MaxSectorWeight float64 `json:"max_sector_weight"`
+	MinScore        float64 `json:"min_score"`
+	Weighting       string  `json:"weighting"`
+}
+
+type HoldingResponse struct {
+	Ticker         string  `json:"ticker"`
+	StockID        string  `json:"stock_id,omitempty"`
+	Sector         string  `json:"sector"`
+	Score          float64 `json:"score"`
+	Recommendation string  `json:"recommendation"`
+	Weight         float64 `json:"weight"`
+	Quantity       float64 `json:"quantity,omitempty"`
+	CostBasis      float64 `json:"cost_basis,omitempty"`
+}
+
+type PortfolioResponse struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	Kind string `json:"kind"`
+	// Constraints are only set on model portfolios
+	Constraints *PortfolioConstraintsResponse `json:"constraints,omitempty"`
+	Holdings    []HoldingResponse             `json:"holdings,omitempty"`
+	Cash        float64                       `json:"cash"`
+	DataVersion int64                         `json:"data_version"`
+	CreatedAt   time.Time                     `json:"created_at"`
+}
+
+type PortfoliosResponse struct {
+	Portfolios []PortfolioResponse `json:"portfolios"`
+	Count      int                 `json:"count"`
+}
+
+func (r ConstructPortfolioRequest) ToConstraints() portfolio.Constraints {
+	weighting := portfolio.Weighting(r.Weighting)
+	if weighting == "" {
+		weighting = portfolio.WeightByScore
+	}
+	return portfolio.Constraints{
+		MaxNames:        r.MaxNames,
+		MaxWeight:       r.MaxWeight,
+		MaxSectorWeight: r.MaxSectorWeight,
+		MinScore:        r.MinScore,
+		Weighting:       weighting,
+	}
+}
+
+func (r UploadHoldingsRequest) ToHoldings() []portfolio.Holding {
+	holdings := make([]portfolio.Holding, len(r.Holdings))
+	for i, entry := range r.Holdings {
+		holdings[i] = portfolio.Holding{
+			Ticker:    stock.Ticker(entry.Ticker),
+			Quantity:  entry.Quantity,
+			CostBasis: entry.CostBasis,
+		}
+	}
+	return holdings
+}
+
+func ToPortfolioResponse(p *portfolio.Portfolio) PortfolioResponse {
+	holdings := make([]HoldingResponse, len(p.Holdings))
+	for i, h := range p.Holdings {
+		holdings[i] = HoldingResponse{
+			Ticker:         h.Ticker.String(),
+			Sector:         h.Sector,
+			Score:          h.Score,
+			Recommendation: h.Recommendation,
+			Weight:         h.Weight,
+			Quantity:       h.Quantity,
+			CostBasis:      h.CostBasis,
+		}
+		if h.StockID != uuid.Nil {
+			holdings[i].StockID = h.StockID.String()
+		}
+	}
+
+	var constraints *PortfolioConstraintsResponse
+	if p.Kind == portfolio.KindModel {
+		constraints = &PortfolioConstraintsResponse{
+			MaxNames:        p.Constraints.MaxNames,
+			MaxWeight:       p.Constraints.MaxWeight,
+			MaxSectorWeight: p.Constraints.MaxSectorWeight,
+			MinScore:        p.Constraints.MinScore,
+			Weighting:       string(p.Constraints.Weighting),
+		}
+	}
+
+	return PortfolioResponse{
+		ID:          p.ID.String(),
+		Name:        p.Name,
+		Kind:        string(p.Kind),
+		Constraints: constraints,
+		Holdings:    holdings,
+		Cash:        p.Cash,
+		DataVersion: p.DataVersion,
+		CreatedAt:   p.CreatedAt,
+	}
+}
+
+// ToPortfoliosResponse lists portfolios without their holdings
+func ToPortfoliosResponse(portfolios []portfolio.Portfolio) PortfoliosResponse {
+	responses := make([]PortfolioResponse, len(portfolios))
+	for i := range portfolios {
+		responses[i] = ToPortfolioResponse(&portfolios[i])
+	}
+	return PortfoliosResponse{
+		Portfolios: responses,
+		Count:      len(responses),
+	}
+}
@@ -0,0 +1,77 @@
+package dto
+
+import (
+	"encoding/json"
+	"stockapi/internal/application/services"
+	"stockapi/internal/domain/stock"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RejectedRecordResponse struct {
+	ID         string          `json:"id"`
+	RunID      string          `json:"run_id,omitempty"`
+	Source     string          `json:"source"`
+	Reason     string          `json:"reason"`
+	Status     string          `json:"status"`
+	ReceivedAt time.Time       `json:"received_at"`
+	ResolvedAt *time.Time      `json:"resolved_at,omitempty"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+type RejectedRecordsResponse struct {
+	Records []RejectedRecordResponse `json:"records"`
+	Count   int                      `json:"count"`
+}
+
+// ReprocessRequest optionally replaces the quarantined payload with a
+// corrected one before it is parsed again
+type ReprocessRequest struct {
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+type ReprocessResponse struct {
+	Record RejectedRecordResponse `json:"record"`
+	Stock  *StockResponse         `json:"stock,omitempty"`
+}
+
+func ToRejectedRecordResponse(record *stock.RejectedRecord) RejectedRecordResponse {
+	response := RejectedRecordResponse{
+		ID:         record.ID.String(),
+		Source:     record.Source,
+		Reason:     record.Reason,
+		Status:     string(record.Status),
+		ReceivedAt: record.ReceivedAt,
+		Payload:    rawPayload(record.Payload),
+	}
+	if record.RunID != uuid.Nil {
+		response.RunID = record.RunID.String()
+	}
+	if !record.ResolvedAt.IsZero() {
+		response.ResolvedAt = &record.ResolvedAt
+	}
+	return response
+}
+
+func ToRejectedRecordsResponse(records []stock.RejectedRecord) RejectedRecordsResponse {
+	responses := make([]RejectedRecordResponse, len(records))
+	for i := range records {
+		responses[i] = ToRejectedRecordResponse(&records[i])
+	}
+	return RejectedRecordsResponse{
+		Records: responses,
+		Count:   len(responses),
+	}
+}
+
+func ToReprocessResponse(result *services.ReprocessResult) ReprocessResponse {
+	response := ReprocessResponse{
+		Record: ToRejectedRecordResponse(result.Record),
+	}
+	if result.Stock != nil {
+		stockResponse := ToStockResponse(result.Stock)
+		response.Stock = &stockResponse
+	}
+	return response
+}
@@ -0,0 +1,87 @@
+package dto
+
+import (
+	"stockapi/internal/domain/portfolio"
+	"time"
+)
+
+type BrokerRatingResponse struct {
+	Brokerage    string    `json:"brokerage"`
+	Tier         string    `json:"tier"`
+	Action       string    `json:"action"`
+	RatingFrom   string    `json:"rating_from"`
+	RatingTo     string    `json:"rating_to"`
+	TargetFrom   float64   `json:"target_from"`
+	TargetTo     float64   `json:"target_to"`
+	TargetChange float64   `json:"target_change"`
+	Time         time.Time `json:"time"`
+}
+
+type HoldingReviewResponse struct {
+	Ticker          string                 `json:"ticker"`
+	Company         string                 `json:"company,omitempty"`
+	Quantity        float64                `json:"quantity,omitempty"`
+	CostBasis       float64                `json:"cost_basis,omitempty"`
+	Weight          float64                `json:"weight"`
+	Ratings         []BrokerRatingResponse `json:"ratings"`
+	Downgrades      []BrokerRatingResponse `json:"downgrades"`
+	ConsensusTarget float64                `json:"consensus_target,omitempty"`
+	TargetUpside    *float64               `json:"target_upside,omitempty"`
+	RiskFlag        bool                   `json:"risk_flag"`
+	RiskBrokers     []string               `json:"risk_brokers,omitempty"`
+}
+
+type PortfolioReviewResponse struct {
+	PortfolioID string                  `json:"portfolio_id"`
+	Name        string                  `json:"name,omitempty"`
+	Kind        string                  `json:"kind"`
+	Since       time.Time               `json:"since"`
+	Flagged     int                     `json:"flagged"`
+	Holdings    []HoldingReviewResponse `json:"holdings"`
+}
+
+func ToBrokerRatingResponses(ratings []portfolio.BrokerRating) []BrokerRatingResponse {
+	responses := make([]BrokerRatingResponse, len(ratings))
+	for i, r := range ratings {
+		responses[i] = BrokerRatingResponse{
+			Brokerage:    r.Brokerage,
+			Tier:         r.Tier.String(),
+			Action:       r.Action,
+			RatingFrom:   string(r.Rating.From),
+			RatingTo:     string(r.Rating.To),
+			TargetFrom:   r.Target.From.Amount,
+			TargetTo:     r.Target.To.Amount,
+			TargetChange: r.TargetChange(),
+			Time:         r.Time,
+		}
+	}
+	return responses
+}
+
+func ToPortfolioReviewResponse(review *portfolio.Review) PortfolioReviewResponse {
+	holdings := make([]HoldingReviewResponse, len(review.Holdings))
+	for i, h := range review.Holdings {
+		holdings[i] = HoldingReviewResponse{
+			Ticker:          h.Holding.Ticker.String(),
+			Company:         h.Company,
+			Quantity:        h.Holding.Quantity,
+			CostBasis:       h.Holding.CostBasis,
+			Weight:          h.Holding.Weight,
+			Ratings:         ToBrokerRatingResponses(h.Ratings),
+			Downgrades:      ToBrokerRatingResponses(h.Downgrades),
+			ConsensusTarget: h.ConsensusTarget,
+			TargetUpside:    h.TargetUpside,
+			RiskFlag:        h.RiskFlag(),
+			RiskBrokers:     h.RiskBrokers,
+		}
+	}
+
+	return PortfolioReviewResponse{
+		PortfolioID: review.Portfolio.ID.String(),
+		Name:        review.Portfolio.Name,
+		Kind:        string(review.Portfolio.Kind),
+		Since:       review.Since,
+		Flagged:     review.Flagged(),
+		Holdings:    holdings,
+	}
+}
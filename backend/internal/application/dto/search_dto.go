@@ -0,0 +1,35 @@
+package dto
+
+import "stockapi/internal/domain/stock"
+
+type SearchHitResponse struct {
+	MatchedField string        `json:"matched_field"`
+	Rank         float64       `json:"rank"`
+	Stock        StockResponse `json:"stock"`
+	// MatchedBrokerage is the best matching broker that rated the stock,
+	// which may differ from the one of its latest action
+	MatchedBrokerage string `json:"matched_brokerage,omitempty"`
+}
+
+type SearchResponse struct {
+	Query   string              `json:"query"`
+	Results []SearchHitResponse `json:"results"`
+	Count   int                 `json:"count"`
+}
+
+func ToSearchResponse(query string, hits []stock.SearchHit) SearchResponse {
+	results := make([]SearchHitResponse, len(hits))
+	for i, hit := range hits {
+		results[i] = SearchHitResponse{
+			MatchedField:     string(hit.Field),
+			Rank:             hit.Rank,
+			Stock:            ToStockResponse(hit.Stock),
+			MatchedBrokerage: hit.Brokerage,
+		}
+	}
+	return SearchResponse{
+		Query:   query,
+		Results: results,
+		Count:   len(results),
+	}
+}
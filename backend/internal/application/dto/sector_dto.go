@@ -0,0 +1,78 @@
+package dto
+
+import (
+	"stockapi/internal/application/services"
+	"stockapi/internal/domain/analysis"
+)
+
+type SectorSummaryResponse struct {
+	Sector              string  `json:"sector"`
+	Industry            string  `json:"industry,omitempty"`
+	Stocks              int     `json:"stocks"`
+	Upgrades            int     `json:"upgrades"`
+	Downgrades          int     `json:"downgrades"`
+	NetSentiment        int     `json:"net_sentiment"`
+	AverageTargetGrowth float64 `json:"average_target_growth"`
+	AverageScore        float64 `json:"average_score"`
+	Analyzed            int     `json:"analyzed"`
+}
+
+type SectorDetailResponse struct {
+	SectorSummaryResponse
+	Industries []SectorSummaryResponse `json:"industries"`
+}
+
+type ClassificationImportResponse struct {
+	Imported int `json:"imported"`
+}
+
+type ImportLineErrorResponse struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// ImportErrorResponse lists the lines that rejected an import
+type ImportErrorResponse struct {
+	Error string                    `json:"error"`
+	Lines []ImportLineErrorResponse `json:"lines"`
+}
+
+func ToSectorSummaryResponse(summary analysis.SectorSummary) SectorSummaryResponse {
+	return SectorSummaryResponse{
+		Sector:              summary.Sector,
+		Industry:            summary.Industry,
+		Stocks:              summary.Stocks,
+		Upgrades:            summary.Upgrades,
+		Downgrades:          summary.Downgrades,
+		NetSentiment:        summary.NetSentiment(),
+		AverageTargetGrowth: summary.AverageTargetGrowth,
+		AverageScore:        summary.AverageScore,
+		Analyzed:            summary.Analyzed,
+	}
+}
+
+func ToSectorSummariesResponse(summaries []analysis.SectorSummary) []SectorSummaryResponse {
+	responses := make([]SectorSummaryResponse, len(summaries))
+	for i, summary := range summaries {
+		responses[i] = ToSectorSummaryResponse(summary)
+	}
+	return responses
+}
+
+func ToSectorDetailResponse(detail *services.SectorDetail) SectorDetailResponse {
+	return SectorDetailResponse{
+		SectorSummaryResponse: ToSectorSummaryResponse(detail.Summary),
+		Industries:            ToSectorSummariesResponse(detail.Industries),
+	}
+}
+
+func ToImportErrorResponse(err *services.ImportError) ImportErrorResponse {
+	lines := make([]ImportLineErrorResponse, len(err.Lines))
+	for i, line := range err.Lines {
+		lines[i] = ImportLineErrorResponse{Line: line.Line, Message: line.Message}
+	}
+	return ImportErrorResponse{
+		Error: err.Error(),
+		Lines: lines,
+	}
+}
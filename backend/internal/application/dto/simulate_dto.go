@@ -0,0 +1,72 @@
+package dto
+
+import (
+	"stockapi/internal/domain/analysis"
+	"stockapi/internal/domain/stock"
+	"time"
+)
+
+// StockFieldsRequest holds the fields of a rating event a request sets;
+// omitted fields are left as they are
+type StockFieldsRequest struct {
+	Company    *string    `json:"company,omitempty"`
+	Brokerage  *string    `json:"brokerage,omitempty"`
+	Action     *string    `json:"action,omitempty"`
+	RatingFrom *string    `json:"rating_from,omitempty"`
+	RatingTo   *string    `json:"rating_to,omitempty"`
+	TargetFrom *float64   `json:"target_from,omitempty"`
+	TargetTo   *float64   `json:"target_to,omitempty"`
+	Time       *time.Time `json:"time,omitempty"`
+}
+
+// SimulateRequest is a hypothetical rating event. Omitted fields keep the
+// values of the stored stock for the ticker.
+type SimulateRequest struct {
+	Ticker string `json:"ticker"`
+	StockFieldsRequest
+}
+
+type SimulationResponse struct {
+	ScoreExplanationResponse
+	// Base is the stored stock the scenario modifies
+	Base *StockResponse `json:"base,omitempty"`
+}
+
+func (r SimulateRequest) ToScenario() analysis.Scenario {
+	return analysis.Scenario{
+		Ticker: r.Ticker,
+		Edit:   r.ToEdit(),
+	}
+}
+
+func (r StockFieldsRequest) ToEdit() stock.Edit {
+	return stock.Edit{
+		Company:    r.Company,
+		Brokerage:  r.Brokerage,
+		Action:     r.Action,
+		RatingFrom: toRating(r.RatingFrom),
+		RatingTo:   toRating(r.RatingTo),
+		TargetFrom: r.TargetFrom,
+		TargetTo:   r.TargetTo,
+		Time:       r.Time,
+	}
+}
+
+func toRating(value *string) *stock.Rating {
+	if value == nil {
+		return nil
+	}
+	rating := stock.Rating(*value)
+	return &rating
+}
+
+func ToSimulationResponse(simulation *analysis.Simulation) SimulationResponse {
+	response := SimulationResponse{
+		ScoreExplanationResponse: ToScoreExplanationResponse(&simulation.StockExplanation),
+	}
+	if simulation.Base != nil {
+		base := ToStockResponse(simulation.Base)
+		response.Base = &base
+	}
+	return response
+}
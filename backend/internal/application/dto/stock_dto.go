@@ -17,6 +17,16 @@ type StockResponse struct {
 	RatingFrom string    `json:"rating_from"`
 	RatingTo   string    `json:"rating_to"`
 	Time       time.Time `json:"time"`
+	Source     string    `json:"source"`
+
+	Classification *ClassificationResponse `json:"classification,omitempty"`
+}
+
+type ClassificationResponse struct {
+	Sector    string `json:"sector"`
+	Industry  string `json:"industry,omitempty"`
+	MarketCap string `json:"market_cap,omitempty"`
+	Exchange  string `json:"exchange,omitempty"`
 }
 
 type AnalysisResponse struct {
@@ -24,12 +34,60 @@ type AnalysisResponse struct {
 	Score          float64            `json:"score"`
 	Indicators     map[string]float64 `json:"indicators"`
 	Recommendation string             `json:"recommendation"`
+	Stale          bool               `json:"stale"`
+}
+
+// ExcludedStockResponse is a stock left out of the recommendations
+type ExcludedStockResponse struct {
+	Stock  StockResponse     `json:"stock"`
+	Reason ExclusionResponse `json:"reason"`
+}
+
+// RecommendationsResponse is the recommendation list along with the stocks
+// left out of it
+type RecommendationsResponse struct {
+	Recommendations []AnalysisResponse      `json:"recommendations"`
+	Excluded        []ExcludedStockResponse `json:"excluded"`
+}
+
+// StockExportColumns are the column headers matching StockResponse.ExportValues
+var StockExportColumns = []string{
+	"id", "ticker", "target_from", "target_to", "company",
+	"action", "brokerage", "rating_from", "rating_to", "time", "source",
+	"sector", "industry", "market_cap", "exchange",
+}
+
+// AnalysisExportColumns are the column headers matching AnalysisResponse.ExportValues
+var AnalysisExportColumns = append(
+	append(append([]string{}, StockExportColumns...), "score", "recommendation", "stale"),
+	analysis.IndicatorNames...,
+)
+
+func (r StockResponse) ExportValues() []interface{} {
+	var classification ClassificationResponse
+	if r.Classification != nil {
+		classification = *r.Classification
+	}
+	return []interface{}{
+		r.ID, r.Ticker, r.TargetFrom, r.TargetTo, r.Company,
+		r.Action, r.Brokerage, r.RatingFrom, r.RatingTo, r.Time, r.Source,
+		classification.Sector, classification.Industry, classification.MarketCap, classification.Exchange,
+	}
+}
+
+// ExportValues flattens the analysis, with one column per indicator
+func (r AnalysisResponse) ExportValues() []interface{} {
+	values := append(r.Stock.ExportValues(), r.Score, r.Recommendation, r.Stale)
+	for _, name := range analysis.IndicatorNames {
+		values = append(values, r.Indicators[name])
+	}
+	return values
 }
 
 func ToStockResponse(s *stock.Stock) StockResponse {
-	return StockResponse{
+	response := StockResponse{
 		ID:         s.ID.String(),
-		Ticker:     s.Ticker,
+		Ticker:     s.Ticker.String(),
 		TargetFrom: s.Target.From.Amount,
 		TargetTo:   s.Target.To.Amount,
 		Company:    s.Company,
@@ -38,6 +96,23 @@ func ToStockResponse(s *stock.Stock) StockResponse {
 		RatingFrom: string(s.Rating.From),
 		RatingTo:   string(s.Rating.To),
 		Time:       s.Time,
+		Source:     s.Source,
+	}
+	if s.Classification != nil {
+		response.Classification = &ClassificationResponse{
+			Sector:    s.Classification.Sector,
+			Industry:  s.Classification.Industry,
+			MarketCap: string(s.Classification.MarketCap),
+			Exchange:  s.Classification.Exchange,
+		}
+	}
+	return response
+}
+
+func ToExcludedStockResponse(exclusion analysis.Exclusion) ExcludedStockResponse {
+	return ExcludedStockResponse{
+		Stock:  ToStockResponse(exclusion.Stock),
+		Reason: ToExclusionResponse(exclusion.Reason),
 	}
 }
 
@@ -47,5 +122,6 @@ func ToAnalysisResponse(analysis analysis.StockAnalysis) AnalysisResponse {
 		Score:          analysis.Score,
 		Indicators:     analysis.Indicators,
 		Recommendation: analysis.Recommendation,
+		Stale:          analysis.Stale,
 	}
 }
@@ -0,0 +1,191 @@
+package dto
+
+import (
+	"encoding/json"
+	"fmt"
+	"stockapi/internal/application/services"
+	"stockapi/internal/domain/stock"
+	"time"
+)
+
+type ChangeSummaryResponse struct {
+	New              int    `json:"new"`
+	Changed          int    `json:"changed"`
+	Unchanged        int    `json:"unchanged"`
+	Upgrades         int    `json:"upgrades"`
+	Downgrades       int    `json:"downgrades"`
+	TargetChanges    int    `json:"target_changes"`
+	BrokerageChanges int    `json:"brokerage_changes"`
+	Locked           int    `json:"locked"`
+	Headline         string `json:"headline"`
+}
+
+type SyncRunResponse struct {
+	ID         string                `json:"id"`
+	Status     string                `json:"status"`
+	Full       bool                  `json:"full"`
+	StartedAt  time.Time             `json:"started_at"`
+	FinishedAt *time.Time            `json:"finished_at,omitempty"`
+	Fetched    int                   `json:"fetched"`
+	Rejected   int                   `json:"rejected"`
+	Summary    ChangeSummaryResponse `json:"summary"`
+	Error      string                `json:"error,omitempty"`
+}
+
+type SyncResultResponse struct {
+	Message string          `json:"message"`
+	Run     SyncRunResponse `json:"run"`
+}
+
+// SyncInProgressResponse identifies the sync that holds the lease
+type SyncInProgressResponse struct {
+	Error     string     `json:"error"`
+	RunID     string     `json:"run_id"`
+	Holder    string     `json:"holder"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type FieldChangeResponse struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+type StockChangeResponse struct {
+	Ticker    string                `json:"ticker"`
+	Kind      string                `json:"kind"`
+	Direction string                `json:"direction,omitempty"`
+	Fields    []FieldChangeResponse `json:"fields"`
+}
+
+type SyncChangesResponse struct {
+	Run     SyncRunResponse       `json:"run"`
+	Changes []StockChangeResponse `json:"changes"`
+}
+
+func ToSyncRunResponse(run *stock.SyncRun) SyncRunResponse {
+	response := SyncRunResponse{
+		ID:        run.ID.String(),
+		Status:    string(run.Status),
+		Full:      run.Full,
+		StartedAt: run.StartedAt,
+		Fetched:   run.Fetched,
+		Rejected:  run.Rejected,
+		Summary:   ToChangeSummaryResponse(run.Summary),
+		Error:     run.Error,
+	}
+	if !run.FinishedAt.IsZero() {
+		response.FinishedAt = &run.FinishedAt
+	}
+	return response
+}
+
+func ToSyncInProgressResponse(err *stock.SyncInProgressError) SyncInProgressResponse {
+	lease := err.Lease
+	response := SyncInProgressResponse{
+		Error:  err.Error(),
+		RunID:  lease.RunID.String(),
+		Holder: lease.Holder,
+	}
+	if !lease.AcquiredAt.IsZero() {
+		response.StartedAt = &lease.AcquiredAt
+		response.ExpiresAt = &lease.ExpiresAt
+	}
+	return response
+}
+
+func ToChangeSummaryResponse(summary stock.ChangeSummary) ChangeSummaryResponse {
+	return ChangeSummaryResponse{
+		New:              summary.New,
+		Changed:          summary.Changed,
+		Unchanged:        summary.Unchanged,
+		Upgrades:         summary.Upgrades,
+		Downgrades:       summary.Downgrades,
+		TargetChanges:    summary.TargetChanges,
+		BrokerageChanges: summary.BrokerageChanges,
+		Locked:           summary.Locked,
+		Headline: fmt.Sprintf("%d new, %d upgrades, %d downgrades, %d target changes",
+			summary.New, summary.Upgrades, summary.Downgrades, summary.TargetChanges),
+	}
+}
+
+type ParseFailureResponse struct {
+	Source  string          `json:"source"`
+	Reason  string          `json:"reason"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+type DryRunResponse struct {
+	DryRun        bool                   `json:"dry_run"`
+	Full          bool                   `json:"full"`
+	Fetched       int                    `json:"fetched"`
+	Summary       ChangeSummaryResponse  `json:"summary"`
+	ParseFailures []ParseFailureResponse `json:"parse_failures"`
+	Changes       []StockChangeResponse  `json:"changes"`
+}
+
+func ToSyncChangesResponse(run *stock.SyncRun, changes []stock.StockChange) SyncChangesResponse {
+	return SyncChangesResponse{
+		Run:     ToSyncRunResponse(run),
+		Changes: ToStockChangeResponses(changes),
+	}
+}
+
+func ToDryRunResponse(report *services.DryRunReport) DryRunResponse {
+	failures := make([]ParseFailureResponse, len(report.ParseFailures))
+	for i, failure := range report.ParseFailures {
+		failures[i] = ToParseFailureResponse(failure)
+	}
+	return DryRunResponse{
+		DryRun:        true,
+		Full:          report.Full,
+		Fetched:       report.Fetched,
+		Summary:       ToChangeSummaryResponse(report.Summary),
+		ParseFailures: failures,
+		Changes:       ToStockChangeResponses(report.Changes),
+	}
+}
+
+func ToParseFailureResponse(record stock.RejectedRecord) ParseFailureResponse {
+	return ParseFailureResponse{
+		Source:  record.Source,
+		Reason:  record.Reason,
+		Payload: rawPayload(record.Payload),
+	}
+}
+
+// rawPayload embeds a record payload as JSON, quoting it when it is not
+func rawPayload(payload []byte) json.RawMessage {
+	raw := json.RawMessage(payload)
+	if !json.Valid(raw) {
+		raw, _ = json.Marshal(string(payload))
+	}
+	return raw
+}
+
+func ToStockChangeResponses(changes []stock.StockChange) []StockChangeResponse {
+	responses := make([]StockChangeResponse, len(changes))
+	for i, change := range changes {
+		fields := make([]FieldChangeResponse, len(change.Fields))
+		for j, field := range change.Fields {
+			fields[j] = FieldChangeResponse{Field: field.Field, From: field.From, To: field.To}
+		}
+
+		var direction string
+		switch {
+		case change.Direction > 0:
+			direction = "upgrade"
+		case change.Direction < 0:
+			direction = "downgrade"
+		}
+
+		responses[i] = StockChangeResponse{
+			Ticker:    change.Ticker.String(),
+			Kind:      string(change.Kind),
+			Direction: direction,
+			Fields:    fields,
+		}
+	}
+	return responses
+}
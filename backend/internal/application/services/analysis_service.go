@@ -2,23 +2,326 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"stockapi/internal/domain/analysis"
+	"stockapi/internal/domain/shared"
+	"stockapi/internal/domain/stock"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// recommendedKey caches the full recommendation list
+	recommendedKey = "recommended"
+	// customKeyPrefix caches the recommendations for request options
+	customKeyPrefix = "custom:"
 )
 
 type AnalysisApplicationService struct {
 	analysisService *analysis.AnalysisService
+	versions        stock.DataVersionRepository
+	// snapshots persists computed analyses for other replicas and restarts;
+	// nil keeps the cache in memory only
+	snapshots analysis.SnapshotRepository
+	history   analysis.ScoreHistoryRepository
+	logger    shared.Logger
+	cacheTTL  time.Duration
+	// historyRetention is how long score points are kept; zero keeps them
+	// forever
+	historyRetention time.Duration
+
+	// mu serializes computations so a burst of misses rescores only once
+	mu    sync.Mutex
+	cache map[string]*analysis.Snapshot
+
+	// stopHistory cancels the score history recorder, which closes
+	// historyDone once it returns
+	stopHistory context.CancelFunc
+	historyDone chan struct{}
 }
 
-func NewAnalysisApplicationService(service *analysis.AnalysisService) *AnalysisApplicationService {
+func NewAnalysisApplicationService(
+	service *analysis.AnalysisService,
+	versions stock.DataVersionRepository,
+	snapshots analysis.SnapshotRepository,
+	history analysis.ScoreHistoryRepository,
+	logger shared.Logger,
+	cacheTTL time.Duration,
+	historyRetention time.Duration,
+) *AnalysisApplicationService {
 	return &AnalysisApplicationService{
-		analysisService: service,
+		analysisService:  service,
+		versions:         versions,
+		snapshots:        snapshots,
+		history:          history,
+		logger:           logger,
+		cacheTTL:         cacheTTL,
+		historyRetention: historyRetention,
+		cache:            make(map[string]*analysis.Snapshot),
+	}
+}
+
+// DefaultOptions returns the configured analysis options, which requests
+// override field by field
+func (s *AnalysisApplicationService) DefaultOptions() analysis.Options {
+	return s.analysisService.DefaultOptions()
+}
+
+// AnalyzeAllStocks returns the scored recommendations, best first. Results are
+// cached per options and reused until the stock data version changes, a stock
+// goes stale or the cache TTL expires. Only the default options are shared
+// through snapshots.
+func (s *AnalysisApplicationService) AnalyzeAllStocks(ctx context.Context, opts analysis.Options) (*analysis.Snapshot, error) {
+	version, err := s.versions.CurrentDataVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	key := recommendedKey
+	defaults := opts == s.DefaultOptions()
+	if !defaults {
+		key = customKeyPrefix + opts.Key()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if cached, ok := s.cache[key]; ok && cached.Current(version.Version, now) {
+		return cached, nil
+	}
+	if defaults {
+		if snapshot := s.loadSnapshot(ctx, key, version.Version, now); snapshot != nil {
+			s.cache[key] = snapshot
+			return snapshot, nil
+		}
+	}
+
+	snapshot, err := s.computeSnapshot(ctx, key, version.Version, opts)
+	if err != nil {
+		return nil, err
+	}
+	s.evictExpired(now)
+	s.cache[key] = snapshot
+	if defaults {
+		s.saveSnapshot(ctx, snapshot)
+	}
+	return snapshot, nil
+}
+
+// evictExpired drops the cached results that can no longer be served, so
+// request options do not grow the cache without bound. Callers hold mu.
+func (s *AnalysisApplicationService) evictExpired(now time.Time) {
+	for key, snapshot := range s.cache {
+		if !now.Before(snapshot.ExpiresAt) {
+			delete(s.cache, key)
+		}
+	}
+}
+
+func (s *AnalysisApplicationService) computeSnapshot(ctx context.Context, key string, version int64, opts analysis.Options) (*analysis.Snapshot, error) {
+	result, err := s.analysisService.AnalyzeStocks(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	snapshot := &analysis.Snapshot{
+		Key:         key,
+		DataVersion: version,
+		ComputedAt:  now,
+		ExpiresAt:   now.Add(s.cacheTTL),
+		Result:      *result,
+	}
+	staleAt := analysis.StaleAt(result.Analyses, opts.StalenessWindow)
+	if !staleAt.IsZero() && staleAt.Before(snapshot.ExpiresAt) {
+		snapshot.ExpiresAt = staleAt
+	}
+	return snapshot, nil
+}
+
+func (s *AnalysisApplicationService) ExplainStock(ctx context.Context, ticker stock.Ticker, opts analysis.Options) (*analysis.StockExplanation, error) {
+	return s.analysisService.ExplainStock(ctx, ticker, opts)
+}
+
+// Simulate scores a hypothetical rating event without persisting it
+func (s *AnalysisApplicationService) Simulate(ctx context.Context, scenario analysis.Scenario, opts analysis.Options) (*analysis.Simulation, error) {
+	return s.analysisService.Simulate(ctx, scenario, opts)
+}
+
+// SectorDetail is the summary of a sector with its industries
+type SectorDetail struct {
+	Summary    analysis.SectorSummary
+	Industries []analysis.SectorSummary
+}
+
+// SectorSummaries aggregates the analyst sentiment of every sector over the
+// rating actions since the given time
+func (s *AnalysisApplicationService) SectorSummaries(ctx context.Context, since time.Time) ([]analysis.SectorSummary, error) {
+	snapshot, err := s.AnalyzeAllStocks(ctx, s.DefaultOptions())
+	if err != nil {
+		return nil, err
 	}
+	return analysis.SummarizeSectors(&snapshot.Result, since), nil
 }
 
-func (s *AnalysisApplicationService) AnalyzeAllStocks(ctx context.Context) ([]analysis.StockAnalysis, error) {
-	analyses, err := s.analysisService.AnalyzeStocks(ctx)
+// SectorDetail aggregates one sector, matched case-insensitively, by industry
+func (s *AnalysisApplicationService) SectorDetail(ctx context.Context, sector string, since time.Time) (*SectorDetail, error) {
+	snapshot, err := s.AnalyzeAllStocks(ctx, s.DefaultOptions())
 	if err != nil {
 		return nil, err
 	}
-	return analyses, nil
+	for _, summary := range analysis.SummarizeSectors(&snapshot.Result, since) {
+		if strings.EqualFold(summary.Sector, sector) {
+			return &SectorDetail{
+				Summary:    summary,
+				Industries: analysis.SummarizeIndustries(&snapshot.Result, summary.Sector, since),
+			}, nil
+		}
+	}
+	return nil, stock.ErrSectorNotFound
+}
+
+// ScoreHistory is a ticker's recorded scores with the recommendation changes
+type ScoreHistory struct {
+	Ticker stock.Ticker
+	From   time.Time
+	To     time.Time
+	Points []analysis.ScorePoint
+	Flips  []analysis.RecommendationFlip
+}
+
+func (s *AnalysisApplicationService) GetScoreHistory(ctx context.Context, ticker stock.Ticker, from, to time.Time) (*ScoreHistory, error) {
+	points, err := s.history.FindScores(ctx, ticker, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching score history: %w", err)
+	}
+	return &ScoreHistory{
+		Ticker: ticker,
+		From:   from,
+		To:     to,
+		Points: points,
+		Flips:  analysis.DetectFlips(points),
+	}, nil
+}
+
+// StartScoreHistory records the default scores now and then every interval
+// until Shutdown, so the history follows decay and staleness even when the
+// data does not change and no request asks for the recommendations
+func (s *AnalysisApplicationService) StartScoreHistory(interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stopHistory = cancel
+	s.historyDone = make(chan struct{})
+
+	go func() {
+		defer close(s.historyDone)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			s.RecordScores(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Shutdown stops the score history recorder and waits for it to return or for
+// ctx to expire
+func (s *AnalysisApplicationService) Shutdown(ctx context.Context) error {
+	if s.stopHistory == nil {
+		return nil
+	}
+	s.stopHistory()
+
+	select {
+	case <-s.historyDone:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("waiting for score history: %w", ctx.Err())
+	}
+}
+
+// RecordScores adds the current default scores to the history, keeping the
+// latest run of each day per ticker, and prunes the points older than the
+// retention. History is best effort and failures are only logged.
+func (s *AnalysisApplicationService) RecordScores(ctx context.Context) {
+	snapshot, err := s.AnalyzeAllStocks(ctx, s.DefaultOptions())
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Warn(ctx, "Failed to analyze stocks for score history", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+		return
+	}
+
+	points := make([]analysis.ScorePoint, len(snapshot.Analyses))
+	for i, a := range snapshot.Analyses {
+		points[i] = analysis.ScorePointOf(a)
+		points[i].DataVersion = snapshot.DataVersion
+	}
+	if err := s.history.SaveScores(ctx, points); err != nil {
+		s.logger.Warn(ctx, "Failed to record score history", map[string]interface{}{
+			"points": len(points),
+			"error":  err.Error(),
+		})
+	}
+
+	if s.historyRetention <= 0 {
+		return
+	}
+	if _, err := s.history.PruneScores(ctx, time.Now().Add(-s.historyRetention)); err != nil {
+		s.logger.Warn(ctx, "Failed to prune score history", map[string]interface{}{
+			"retention": s.historyRetention.String(),
+			"error":     err.Error(),
+		})
+	}
+}
+
+// loadSnapshot returns the persisted snapshot when it is still current. Any
+// failure only means the analyses are computed again.
+func (s *AnalysisApplicationService) loadSnapshot(ctx context.Context, key string, version int64, now time.Time) *analysis.Snapshot {
+	if s.snapshots == nil {
+		return nil
+	}
+
+	snapshot, err := s.snapshots.FindSnapshot(ctx, key)
+	if err != nil {
+		if !errors.Is(err, stock.ErrSnapshotNotFound) {
+			s.logger.Warn(ctx, "Failed to load analysis snapshot", map[string]interface{}{
+				"key":   key,
+				"error": err.Error(),
+			})
+		}
+		return nil
+	}
+	if !snapshot.Current(version, now) {
+		return nil
+	}
+	return snapshot
+}
+
+func (s *AnalysisApplicationService) saveSnapshot(ctx context.Context, snapshot *analysis.Snapshot) {
+	if s.snapshots == nil {
+		return
+	}
+	if err := s.snapshots.SaveSnapshot(ctx, snapshot); err != nil {
+		s.logger.Warn(ctx, "Failed to save analysis snapshot", map[string]interface{}{
+			"key":   snapshot.Key,
+			"error": err.Error(),
+		})
+	}
+}
+
+func (s *AnalysisApplicationService) StreamAnalyses(ctx context.Context, opts analysis.Options, fn func(analysis.StockAnalysis) error) error {
+	if err := s.analysisService.StreamAnalyses(ctx, opts, fn); err != nil {
+		return fmt.Errorf("error streaming analyses: %w", err)
+	}
+	return nil
 }
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"stockapi/internal/domain/analysis"
+	"stockapi/internal/domain/shared"
+	"stockapi/internal/domain/stock"
+	"sync"
+	"time"
+)
+
+const (
+	// anomalyCacheResolution is the granularity of the since times anomalies
+	// are cached for; results are then trimmed to the exact time
+	anomalyCacheResolution = time.Hour
+	// anomalyCacheSize is how many since times are cached at once; the least
+	// recently used one is evicted first
+	anomalyCacheSize = 8
+)
+
+// AnomalyQuery narrows the reported anomalies; zero fields are not applied
+type AnomalyQuery struct {
+	Since  time.Time
+	Kind   analysis.AnomalyKind
+	Ticker stock.Ticker
+	Limit  int
+}
+
+type AnomalyService struct {
+	actions  stock.RatingActionRepository
+	versions stock.DataVersionRepository
+	options  analysis.AnomalyOptions
+	logger   shared.Logger
+
+	// mu serializes detections so concurrent misses scan the actions once.
+	// cache holds the anomalies of cacheVersion by rounded since time, most
+	// recently used first.
+	mu           sync.Mutex
+	cacheVersion int64
+	cache        []anomalyCacheEntry
+}
+
+type anomalyCacheEntry struct {
+	since     time.Time
+	anomalies []analysis.Anomaly
+}
+
+func NewAnomalyService(
+	actions stock.RatingActionRepository,
+	versions stock.DataVersionRepository,
+	options analysis.AnomalyOptions,
+	logger shared.Logger,
+) *AnomalyService {
+	return &AnomalyService{
+		actions:  actions,
+		versions: versions,
+		options:  options,
+		logger:   logger,
+	}
+}
+
+// DetectAnomalies reports the unusual rating events since the query time,
+// newest first, judged against the baseline history before it. Detections are
+// reused until the stock data version changes.
+func (s *AnomalyService) DetectAnomalies(ctx context.Context, query AnomalyQuery) ([]analysis.Anomaly, error) {
+	version, err := s.versions.CurrentDataVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	detected, err := s.detect(ctx, version.Version, query.Since.Truncate(anomalyCacheResolution))
+	if err != nil {
+		return nil, err
+	}
+
+	// Brokers cover many tickers, so the ticker filter only applies to the
+	// results and not to the distributions
+	var anomalies []analysis.Anomaly
+	for _, anomaly := range detected {
+		if anomaly.Time.Before(query.Since) {
+			continue
+		}
+		if query.Kind != "" && anomaly.Kind != query.Kind {
+			continue
+		}
+		if query.Ticker != "" && anomaly.Ticker != query.Ticker {
+			continue
+		}
+		anomalies = append(anomalies, anomaly)
+		if query.Limit > 0 && len(anomalies) == query.Limit {
+			break
+		}
+	}
+	return anomalies, nil
+}
+
+// detect returns every anomaly since the given time at the data version,
+// computing them on a cache miss
+func (s *AnomalyService) detect(ctx context.Context, version int64, since time.Time) ([]analysis.Anomaly, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if version != s.cacheVersion {
+		s.cache = nil
+		s.cacheVersion = version
+	}
+	for i, entry := range s.cache {
+		if entry.since.Equal(since) {
+			copy(s.cache[1:i+1], s.cache[:i])
+			s.cache[0] = entry
+			return entry.anomalies, nil
+		}
+	}
+
+	actions, err := s.actions.FindRatingActionsSince(ctx, since.Add(-s.options.Baseline))
+	if err != nil {
+		return nil, fmt.Errorf("error loading rating actions: %w", err)
+	}
+	anomalies := analysis.DetectAnomalies(actions, since, s.options)
+	s.cache = append([]anomalyCacheEntry{{since: since, anomalies: anomalies}}, s.cache...)
+	if len(s.cache) > anomalyCacheSize {
+		s.cache = s.cache[:anomalyCacheSize]
+	}
+
+	s.logger.Debug(ctx, "Anomalies detected", map[string]interface{}{
+		"actions":   len(actions),
+		"anomalies": len(anomalies),
+		"version":   version,
+	})
+	return anomalies, nil
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"stockapi/internal/domain/analysis"
+	"stockapi/internal/domain/shared"
+	"stockapi/internal/domain/stock"
+	"testing"
+	"time"
+)
+
+type countingActions struct {
+	stock.RatingActionRepository
+	loads int
+}
+
+func (a *countingActions) FindRatingActionsSince(ctx context.Context, since time.Time) ([]*stock.Stock, error) {
+	a.loads++
+	return nil, nil
+}
+
+type fixedVersion struct {
+	stock.DataVersionRepository
+	version int64
+}
+
+func (v *fixedVersion) CurrentDataVersion(ctx context.Context) (stock.DataVersion, error) {
+	return stock.DataVersion{Version: v.version}, nil
+}
+
+func (v *fixedVersion) BumpDataVersion(ctx context.Context) (stock.DataVersion, error) {
+	v.version++
+	return stock.DataVersion{Version: v.version}, nil
+}
+
+type discardLogger struct{}
+
+func (discardLogger) Log(context.Context, shared.LogLevel, string, map[string]interface{}) {}
+func (discardLogger) Debug(context.Context, string, map[string]interface{})                {}
+func (discardLogger) Info(context.Context, string, map[string]interface{})                 {}
+func (discardLogger) Warn(context.Context, string, map[string]interface{})                 {}
+func (discardLogger) Error(context.Context, string, map[string]interface{})                {}
+
+func TestAnomalyCacheEviction(t *testing.T) {
+	hour := func(h int) time.Time {
+		return time.Date(2025, 1, 1, h, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name      string
+		queries   []int
+		version   int64
+		then      int
+		wantLoads int
+	}{
+		{
+			name:      "times within the resolution share an entry",
+			queries:   []int{0},
+			then:      0,
+			wantLoads: 1,
+		},
+		{
+			name:      "full cache keeps every entry",
+			queries:   []int{0, 1, 2, 3, 4, 5, 6, 7},
+			then:      0,
+			wantLoads: anomalyCacheSize,
+		},
+		{
+			name:      "overflow evicts the least recently used",
+			queries:   []int{0, 1, 2, 3, 4, 5, 6, 7, 8},
+			then:      0,
+			wantLoads: anomalyCacheSize + 2,
+		},
+		{
+			name:      "a hit makes the entry recently used",
+			queries:   []int{0, 1, 2, 3, 4, 5, 6, 7, 0, 8},
+			then:      0,
+			wantLoads: anomalyCacheSize + 1,
+		},
+		{
+			name:      "evicted entry is the one after the hit",
+			queries:   []int{0, 1, 2, 3, 4, 5, 6, 7, 0, 8},
+			then:      1,
+			wantLoads: anomalyCacheSize + 2,
+		},
+		{
+			name:      "a new data version drops the cache",
+			queries:   []int{0},
+			version:   1,
+			then:      0,
+			wantLoads: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actions := &countingActions{}
+			versions := &fixedVersion{}
+			service := NewAnomalyService(actions, versions, analysis.DefaultAnomalyOptions(), discardLogger{})
+			ctx := context.Background()
+
+			for _, h := range tt.queries {
+				if _, err := service.DetectAnomalies(ctx, AnomalyQuery{Since: hour(h)}); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			versions.version = tt.version
+			if _, err := service.DetectAnomalies(ctx, AnomalyQuery{Since: hour(tt.then).Add(30 * time.Minute)}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if actions.loads != tt.wantLoads {
+				t.Errorf("loads = %d, want %d", actions.loads, tt.wantLoads)
+			}
+			if len(service.cache) > anomalyCacheSize {
+				t.Errorf("cache size = %d, want at most %d", len(service.cache), anomalyCacheSize)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"stockapi/internal/domain/shared"
+	"stockapi/internal/domain/stock"
+	"strings"
+	"time"
+)
+
+// classificationColumns are the CSV columns of a classification import; only
+// ticker and sector are required
+var classificationColumns = []string{"ticker", "sector", "industry", "market_cap", "exchange"}
+
+// ImportLineError is an invalid line of an uploaded file
+type ImportLineError struct {
+	Line    int
+	Message string
+}
+
+// ImportError lists every invalid line of a rejected import
+type ImportError struct {
+	Lines []ImportLineError
+}
+
+func (e *ImportError) Error() string {
+	return fmt.Sprintf("import has %d invalid lines", len(e.Lines))
+}
+
+// lineError rejects an import on a single line
+func lineError(line int, err error) *ImportError {
+	return &ImportError{Lines: []ImportLineError{{Line: line, Message: err.Error()}}}
+}
+
+type ClassificationService struct {
+	repo     stock.ClassificationRepository
+	versions stock.DataVersionRepository
+	logger   shared.Logger
+}
+
+func NewClassificationService(
+	repo stock.ClassificationRepository,
+	versions stock.DataVersionRepository,
+	logger shared.Logger,
+) *ClassificationService {
+	return &ClassificationService{
+		repo:     repo,
+		versions: versions,
+		logger:   logger,
+	}
+}
+
+// ImportCSV stores the classifications of a CSV file with a header line. The
+// import is applied only when every line is valid; otherwise it returns a
+// *ImportError and nothing is stored.
+func (s *ClassificationService) ImportCSV(ctx context.Context, r io.Reader) (int, error) {
+	classifications, err := parseClassifications(r, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	if err := s.repo.SaveClassifications(ctx, classifications); err != nil {
+		return 0, err
+	}
+
+	// Stock responses embed the classification, so cached copies are now outdated
+	if _, err := s.versions.BumpDataVersion(ctx); err != nil {
+		s.logger.Warn(ctx, "Failed to bump data version", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	s.logger.Info(ctx, "Classifications imported", map[string]interface{}{
+		"count": len(classifications),
+	})
+	return len(classifications), nil
+}
+
+func parseClassifications(r io.Reader, now time.Time) ([]stock.Classification, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	index, err := readHeader(reader, classificationColumns, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	var classifications []stock.Classification
+	seen := make(map[stock.Ticker]int)
+	invalid, err := readLines(reader, index, func(line int, field func(string) string) string {
+		ticker, tickerErr := stock.ParseTicker(field("ticker"))
+		c := stock.Classification{
+			Ticker:    ticker,
+			Sector:    field("sector"),
+			Industry:  field("industry"),
+			MarketCap: stock.MarketCapBucket(strings.ToLower(field("market_cap"))),
+			Exchange:  strings.ToUpper(field("exchange")),
+			UpdatedAt: now,
+		}
+
+		switch {
+		case field("ticker") == "":
+			return "ticker is required"
+		case tickerErr != nil:
+			return fmt.Sprintf("invalid ticker %q", field("ticker"))
+		case c.Sector == "":
+			return "sector is required"
+		case c.MarketCap != "" && !c.MarketCap.Valid():
+			return fmt.Sprintf("unknown market_cap %q", c.MarketCap)
+		case seen[c.Ticker] != 0:
+			return fmt.Sprintf("ticker %s already on line %d", c.Ticker, seen[c.Ticker])
+		}
+		seen[c.Ticker] = line
+		classifications = append(classifications, c)
+		return ""
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading classifications: %w", err)
+	}
+
+	if len(invalid) > 0 {
+		return nil, &ImportError{Lines: invalid}
+	}
+	return classifications, nil
+}
+
+// readHeader reads the header line and maps the known columns to their
+// position; the first required columns must be present. Every following line
+// must have as many fields as the header.
+func readHeader(reader *csv.Reader, columns []string, required int) (map[string]int, error) {
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, lineError(1, errors.New("missing header"))
+	}
+	if err != nil {
+		return nil, lineError(1, err)
+	}
+	index, err := columnIndex(header, columns, required)
+	if err != nil {
+		return nil, lineError(1, err)
+	}
+	reader.FieldsPerRecord = len(header)
+	return index, nil
+}
+
+// readLines calls check with every line after the header and collects the
+// messages it returns for invalid lines. Lines with the wrong number of fields
+// are reported without calling check; a quoting error ends the file.
+func readLines(reader *csv.Reader, index map[string]int, check func(line int, field func(string) string) string) ([]ImportLineError, error) {
+	var invalid []ImportLineError
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			invalid = append(invalid, ImportLineError{Line: parseErr.StartLine, Message: parseErr.Err.Error()})
+			if errors.Is(parseErr.Err, csv.ErrFieldCount) {
+				continue
+			}
+			// Quoting errors leave the reader out of sync with the lines
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		field := func(name string) string {
+			if i, ok := index[name]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		if message := check(line, field); message != "" {
+			invalid = append(invalid, ImportLineError{Line: line, Message: message})
+		}
+	}
+	return invalid, nil
+}
+
+// columnIndex maps the known columns to their position in the header
+func columnIndex(header []string, columns []string, required int) (map[string]int, error) {
+	index := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		for _, column := range columns {
+			if name == column {
+				index[name] = i
+			}
+		}
+	}
+	for _, required := range columns[:required] {
+		if _, ok := index[required]; !ok {
+			return nil, fmt.Errorf("header is missing the %s column", required)
+		}
+	}
+	return index, nil
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"stockapi/internal/domain/analysis"
+	"stockapi/internal/domain/shared"
+	"stockapi/internal/domain/stock"
+	"time"
+)
+
+// TickerComparison is one column of a comparison
+type TickerComparison struct {
+	Ticker      stock.Ticker
+	Explanation *analysis.StockExplanation
+	// Ratings holds the latest action of each broker, newest first
+	Ratings   []*stock.Stock
+	Consensus analysis.Consensus
+	// History is the ticker's recorded score on each day of the comparison
+	// timeline
+	History []*float64
+}
+
+// Comparison puts tickers side by side, in the requested order
+type Comparison struct {
+	From    time.Time
+	To      time.Time
+	Days    []time.Time
+	Tickers []TickerComparison
+}
+
+type ComparisonService struct {
+	analysisService *AnalysisApplicationService
+	actions         stock.RatingActionRepository
+	logger          shared.Logger
+}
+
+func NewComparisonService(
+	analysisService *AnalysisApplicationService,
+	actions stock.RatingActionRepository,
+	logger shared.Logger,
+) *ComparisonService {
+	return &ComparisonService{
+		analysisService: analysisService,
+		actions:         actions,
+		logger:          logger,
+	}
+}
+
+// Compare scores the tickers with the given options and lines up their score
+// histories between from and to. History is only recorded with the default
+// staleness window and half-life, so other values return
+// stock.ErrUnrecordedOptions rather than mixing scores of both. Every ticker
+// must be stored; otherwise the error wraps stock.ErrStockNotFound and names
+// the missing ticker.
+func (s *ComparisonService) Compare(ctx context.Context, tickers []stock.Ticker, from, to time.Time, opts analysis.Options) (*Comparison, error) {
+	defaults := s.analysisService.DefaultOptions()
+	if opts.StalenessWindow != defaults.StalenessWindow || opts.HalfLife != defaults.HalfLife {
+		return nil, stock.ErrUnrecordedOptions
+	}
+
+	comparison := &Comparison{
+		From:    from,
+		To:      to,
+		Tickers: make([]TickerComparison, len(tickers)),
+	}
+
+	histories := make(map[stock.Ticker][]analysis.ScorePoint, len(tickers))
+	for i, ticker := range tickers {
+		explanation, err := s.analysisService.ExplainStock(ctx, ticker, opts)
+		if errors.Is(err, stock.ErrStockNotFound) {
+			return nil, fmt.Errorf("%w: %s", stock.ErrStockNotFound, ticker)
+		}
+		if err != nil {
+			return nil, err
+		}
+		history, err := s.analysisService.GetScoreHistory(ctx, ticker, from, to)
+		if err != nil {
+			return nil, err
+		}
+		histories[ticker] = history.Points
+		comparison.Tickers[i] = TickerComparison{
+			Ticker:      ticker,
+			Explanation: explanation,
+		}
+	}
+
+	actions, err := s.actions.FindRatingActions(ctx, tickers)
+	if err != nil {
+		return nil, err
+	}
+	byTicker := make(map[stock.Ticker][]*stock.Stock)
+	for _, a := range actions {
+		byTicker[a.Ticker] = append(byTicker[a.Ticker], a)
+	}
+
+	timeline := analysis.AlignScores(histories, from, to)
+	comparison.Days = timeline.Days
+	for i := range comparison.Tickers {
+		column := &comparison.Tickers[i]
+		column.Ratings = analysis.LatestByBroker(byTicker[column.Ticker])
+		column.Consensus = analysis.ConsensusOf(column.Ratings)
+		column.History = timeline.Scores[column.Ticker]
+	}
+	return comparison, nil
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"stockapi/internal/domain/analysis"
+	"stockapi/internal/domain/shared"
+	"stockapi/internal/domain/stock"
+	"testing"
+	"time"
+)
+
+type missingStocks struct {
+	stock.Repository
+}
+
+func (missingStocks) FindByTicker(ctx context.Context, ticker stock.Ticker) (*stock.Stock, error) {
+	return nil, stock.ErrStockNotFound
+}
+
+func TestCompareOptions(t *testing.T) {
+	defaults := analysis.DefaultOptions()
+	svc := analysis.NewAnalysisService(missingStocks{}, shared.NewDomainLogger(discardLogger{}), defaults)
+	analysisService := NewAnalysisApplicationService(svc, &fixedVersion{}, nil, nil, discardLogger{}, 0, 0)
+	comparison := NewComparisonService(analysisService, nil, discardLogger{})
+
+	tests := []struct {
+		name    string
+		opts    func(o *analysis.Options)
+		wantErr error
+	}{
+		{"default options", func(o *analysis.Options) {}, stock.ErrStockNotFound},
+		{"stale stocks included", func(o *analysis.Options) { o.IncludeStale = true }, stock.ErrStockNotFound},
+		{"other staleness window", func(o *analysis.Options) { o.StalenessWindow = 48 * time.Hour }, stock.ErrUnrecordedOptions},
+		{"other half-life", func(o *analysis.Options) { o.HalfLife = 0 }, stock.ErrUnrecordedOptions},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := defaults
+			tt.opts(&opts)
+
+			now := time.Now()
+			_, err := comparison.Compare(context.Background(), []stock.Ticker{"AAA", "BBB"}, now.Add(-time.Hour), now, opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"stockapi/internal/domain/shared"
+	"stockapi/internal/domain/stock"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// correctionCurrency is the currency of the targets of records created by hand,
+// as providers report targets without one in dollars
+const correctionCurrency = "USD"
+
+// StockEdit sets the fields of a stock record by hand. Nil fields keep their
+// value on a patch and are left empty on a replacement, except Time, which
+// defaults to now on new and replaced records.
+type StockEdit struct {
+	Ticker *string
+	stock.Edit
+}
+
+// apply sets the edited fields on the stock, which is left unchanged when a
+// field is invalid
+func (e StockEdit) apply(stk *stock.Stock) error {
+	edited := *stk
+	if e.Ticker != nil {
+		ticker, err := stock.ParseTicker(*e.Ticker)
+		if err != nil {
+			return err
+		}
+		edited.Ticker = ticker
+	}
+	e.Edit.Apply(&edited)
+
+	switch {
+	case edited.Target.From.Amount < 0 || edited.Target.To.Amount < 0:
+		return fmt.Errorf("%w: target prices cannot be negative", stock.ErrInvalidCorrection)
+	case edited.Rating.To == "":
+		return fmt.Errorf("%w: rating_to is required", stock.ErrInvalidCorrection)
+	}
+	*stk = edited
+	return nil
+}
+
+// CorrectionHistory is the audit trail of a stock record and the tickers it
+// still holds locked
+type CorrectionHistory struct {
+	StockID     uuid.UUID
+	Locks       []stock.StockLock
+	Corrections []stock.Correction
+}
+
+// CorrectionService lets admins fix the stored record of a ticker when a
+// provider publishes wrong data. Every change is audited and locks the
+// record's tickers so syncs do not overwrite it until it is released.
+type CorrectionService struct {
+	repo        stock.Repository
+	corrections stock.CorrectionRepository
+	versions    stock.DataVersionRepository
+	logger      shared.Logger
+}
+
+func NewCorrectionService(
+	repo stock.Repository,
+	corrections stock.CorrectionRepository,
+	versions stock.DataVersionRepository,
+	logger shared.Logger,
+) *CorrectionService {
+	return &CorrectionService{
+		repo:        repo,
+		corrections: corrections,
+		versions:    versions,
+		logger:      logger,
+	}
+}
+
+// Put creates the record with the given id, or replaces every field of the
+// stored one, and reports whether it was created. The edit needs a ticker.
+func (s *CorrectionService) Put(ctx context.Context, id uuid.UUID, edit StockEdit, admin string) (*stock.Stock, bool, error) {
+	before, err := s.find(ctx, id)
+	if err != nil && !errors.Is(err, stock.ErrStockNotFound) {
+		return nil, false, err
+	}
+	if edit.Ticker == nil {
+		return nil, false, fmt.Errorf("%w: ticker is required", stock.ErrInvalidCorrection)
+	}
+
+	after := &stock.Stock{
+		ID:     id,
+		Target: stock.TargetPrice{From: stock.Money{Currency: correctionCurrency}, To: stock.Money{Currency: correctionCurrency}},
+		Time:   time.Now(),
+		Source: stock.ManualSource,
+	}
+	if err := edit.apply(after); err != nil {
+		return nil, false, err
+	}
+
+	action := stock.CorrectionReplace
+	if before == nil {
+		action = stock.CorrectionCreate
+	}
+	if err := s.apply(ctx, stock.NewCorrection(action, admin, before, after)); err != nil {
+		return nil, false, err
+	}
+	return after, before == nil, nil
+}
+
+// Patch changes the edited fields of the stored record, which becomes a
+// manual record like a replaced one
+func (s *CorrectionService) Patch(ctx context.Context, id uuid.UUID, edit StockEdit, admin string) (*stock.Stock, error) {
+	before, err := s.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	after := *before
+	after.Source = stock.ManualSource
+	if err := edit.apply(&after); err != nil {
+		return nil, err
+	}
+	if err := s.apply(ctx, stock.NewCorrection(stock.CorrectionPatch, admin, before, &after)); err != nil {
+		return nil, err
+	}
+	return &after, nil
+}
+
+// Delete removes the stored record. Its ticker stays locked, so the next sync
+// does not bring the record back.
+func (s *CorrectionService) Delete(ctx context.Context, id uuid.UUID, admin string) error {
+	before, err := s.find(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.apply(ctx, stock.NewCorrection(stock.CorrectionDelete, admin, before, nil))
+}
+
+// Release unlocks the tickers of a corrected record, so the next sync may
+// overwrite it again. The record is returned, or nil when it was deleted.
+func (s *CorrectionService) Release(ctx context.Context, id uuid.UUID, admin string) (*stock.Stock, error) {
+	locks, err := s.corrections.FindStockLocks(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(locks) == 0 {
+		return nil, stock.ErrStockNotLocked
+	}
+
+	stored, err := s.find(ctx, id)
+	if err != nil && !errors.Is(err, stock.ErrStockNotFound) {
+		return nil, err
+	}
+	correction := stock.NewCorrection(stock.CorrectionRelease, admin, stored, stored)
+	correction.StockID = id
+	if err := s.corrections.ApplyCorrection(ctx, correction); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "Stock correction released", map[string]interface{}{
+		"stock_id": id,
+		"admin":    admin,
+	})
+	return stored, nil
+}
+
+// History returns the latest audit entries of a record, newest first, with
+// its current locks
+func (s *CorrectionService) History(ctx context.Context, id uuid.UUID, limit int) (*CorrectionHistory, error) {
+	corrections, err := s.corrections.FindCorrections(ctx, id, limit)
+	if err != nil {
+		return nil, err
+	}
+	locks, err := s.corrections.FindStockLocks(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(corrections) == 0 && len(locks) == 0 {
+		if _, err := s.find(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+	return &CorrectionHistory{
+		StockID:     id,
+		Locks:       locks,
+		Corrections: corrections,
+	}, nil
+}
+
+func (s *CorrectionService) find(ctx context.Context, id uuid.UUID) (*stock.Stock, error) {
+	stored, err := s.corrections.FindStockByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("error loading stock %s: %w", id, err)
+	}
+	return stored, nil
+}
+
+// apply stores a correction that writes the record, after checking that no
+// other record holds its new ticker
+func (s *CorrectionService) apply(ctx context.Context, c *stock.Correction) error {
+	if c.After != nil {
+		holder, err := s.repo.FindByTicker(ctx, c.After.Ticker)
+		if err != nil && !errors.Is(err, stock.ErrStockNotFound) {
+			return fmt.Errorf("error checking ticker %s: %w", c.After.Ticker, err)
+		}
+		if holder != nil && holder.ID != c.After.ID {
+			return fmt.Errorf("%w: %s", stock.ErrTickerTaken, c.After.Ticker)
+		}
+	}
+
+	if err := s.corrections.ApplyCorrection(ctx, c); err != nil {
+		return err
+	}
+	if _, err := s.versions.BumpDataVersion(ctx); err != nil {
+		return err
+	}
+
+	s.logger.Info(ctx, "Stock corrected by hand", map[string]interface{}{
+		"stock_id": c.StockID,
+		"action":   c.Action,
+		"admin":    c.Admin,
+		"locked":   c.LockedTickers(),
+	})
+	return nil
+}
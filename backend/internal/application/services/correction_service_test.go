@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"stockapi/internal/domain/stock"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type storedCorrections struct {
+	stock.CorrectionRepository
+	stored  *stock.Stock
+	applied []*stock.Correction
+}
+
+func (c *storedCorrections) FindStockByID(ctx context.Context, id uuid.UUID) (*stock.Stock, error) {
+	if c.stored == nil || c.stored.ID != id {
+		return nil, stock.ErrStockNotFound
+	}
+	stored := *c.stored
+	return &stored, nil
+}
+
+func (c *storedCorrections) ApplyCorrection(ctx context.Context, correction *stock.Correction) error {
+	c.applied = append(c.applied, correction)
+	return nil
+}
+
+func TestCorrectionSource(t *testing.T) {
+	company := "Acme Corp"
+	ticker := "ACME"
+	rating := stock.Buy
+
+	tests := []struct {
+		name    string
+		correct func(s *CorrectionService, id uuid.UUID) (*stock.Stock, error)
+	}{
+		{
+			name: "patch",
+			correct: func(s *CorrectionService, id uuid.UUID) (*stock.Stock, error) {
+				edit := StockEdit{Edit: stock.Edit{Company: &company}}
+				return s.Patch(context.Background(), id, edit, "alice")
+			},
+		},
+		{
+			name: "put",
+			correct: func(s *CorrectionService, id uuid.UUID) (*stock.Stock, error) {
+				edit := StockEdit{Ticker: &ticker, Edit: stock.Edit{Company: &company, RatingTo: &rating}}
+				stk, _, err := s.Put(context.Background(), id, edit, "alice")
+				return stk, err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored := &stock.Stock{
+				ID:      uuid.New(),
+				Ticker:  "ACME",
+				Company: "Acme",
+				Rating:  stock.RatingChange{From: stock.Hold, To: stock.Buy},
+				Time:    time.Now(),
+				Source:  "provider",
+			}
+			corrections := &storedCorrections{stored: stored}
+			service := NewCorrectionService(missingStocks{}, corrections, &fixedVersion{}, discardLogger{})
+
+			after, err := tt.correct(service, stored.ID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if after.Source != stock.ManualSource || after.Company != company {
+				t.Errorf("record source = %q, company = %q, want %q and %q", after.Source, after.Company, stock.ManualSource, company)
+			}
+			if len(corrections.applied) != 1 {
+				t.Fatalf("applied corrections = %d, want 1", len(corrections.applied))
+			}
+			applied := corrections.applied[0]
+			if applied.After.Source != stock.ManualSource {
+				t.Errorf("stored source = %q, want %q", applied.After.Source, stock.ManualSource)
+			}
+			if applied.Before.Source != "provider" {
+				t.Errorf("audited source before = %q, want %q", applied.Before.Source, "provider")
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"stockapi/internal/domain/stock"
+	"time"
+)
+
+const (
+	StatusOK          = "ok"
+	StatusDegraded    = "degraded"
+	StatusUnavailable = "unavailable"
+)
+
+// circuitReporter is implemented by StockAPIPort adapters that guard the
+// provider with a circuit breaker
+type circuitReporter interface {
+	CircuitState() string
+}
+
+type CheckResult struct {
+	Status  string
+	Details map[string]interface{}
+}
+
+type ReadinessReport struct {
+	Status    string
+	Checks    map[string]CheckResult
+	CheckedAt time.Time
+}
+
+type HealthService struct {
+	repo         stock.Repository
+	apiPort      stock.StockAPIPort
+	stockService *StockService
+}
+
+func NewHealthService(repo stock.Repository, apiPort stock.StockAPIPort, stockService *StockService) *HealthService {
+	return &HealthService{
+		repo:         repo,
+		apiPort:      apiPort,
+		stockService: stockService,
+	}
+}
+
+// Readiness checks the dependencies needed to serve traffic. Only the database
+// is critical; the sync and the external API only degrade the report.
+func (s *HealthService) Readiness(ctx context.Context) ReadinessReport {
+	checks := map[string]CheckResult{
+		"database":     s.checkDatabase(ctx),
+		"sync":         s.checkSync(),
+		"external_api": s.checkExternalAPI(),
+	}
+
+	status := StatusOK
+	for name, check := range checks {
+		if check.Status == StatusOK {
+			continue
+		}
+		if name == "database" {
+			status = StatusUnavailable
+			break
+		}
+		status = StatusDegraded
+	}
+
+	return ReadinessReport{
+		Status:    status,
+		Checks:    checks,
+		CheckedAt: time.Now(),
+	}
+}
+
+func (s *HealthService) checkDatabase(ctx context.Context) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	if err := s.repo.Ping(ctx); err != nil {
+		return CheckResult{
+			Status:  StatusUnavailable,
+			Details: map[string]interface{}{"error": err.Error()},
+		}
+	}
+	return CheckResult{
+		Status:  StatusOK,
+		Details: map[string]interface{}{"latency_ms": time.Since(start).Milliseconds()},
+	}
+}
+
+func (s *HealthService) checkSync() CheckResult {
+	lastSync := s.stockService.LastSuccessfulSync()
+	if lastSync.IsZero() {
+		return CheckResult{
+			Status:  StatusDegraded,
+			Details: map[string]interface{}{"last_success": nil},
+		}
+	}
+	return CheckResult{
+		Status: StatusOK,
+		Details: map[string]interface{}{
+			"last_success": lastSync,
+			"age_seconds":  int64(time.Since(lastSync).Seconds()),
+		},
+	}
+}
+
+func (s *HealthService) checkExternalAPI() CheckResult {
+	reporter, ok := s.apiPort.(circuitReporter)
+	if !ok {
+		return CheckResult{Status: StatusOK}
+	}
+
+	state := reporter.CircuitState()
+	status := StatusOK
+	if state != "closed" {
+		status = StatusDegraded
+	}
+	return CheckResult{
+		Status:  status,
+		Details: map[string]interface{}{"circuit": state},
+	}
+}
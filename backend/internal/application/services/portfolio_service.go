@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"stockapi/internal/domain/portfolio"
+	"stockapi/internal/domain/shared"
+	"stockapi/internal/domain/stock"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// holdingColumns are the CSV columns of a holdings upload; cost_basis is
+// optional
+var holdingColumns = []string{"ticker", "quantity", "cost_basis"}
+
+type PortfolioService struct {
+	analysisService *AnalysisApplicationService
+	portfolios      portfolio.Repository
+	actions         stock.RatingActionRepository
+	logger          shared.Logger
+}
+
+func NewPortfolioService(
+	analysisService *AnalysisApplicationService,
+	portfolios portfolio.Repository,
+	actions stock.RatingActionRepository,
+	logger shared.Logger,
+) *PortfolioService {
+	return &PortfolioService{
+		analysisService: analysisService,
+		portfolios:      portfolios,
+		actions:         actions,
+		logger:          logger,
+	}
+}
+
+// Construct builds a portfolio from the current recommendations and stores it
+// so it can be compared with later ones
+func (s *PortfolioService) Construct(ctx context.Context, name string, constraints portfolio.Constraints) (*portfolio.Portfolio, error) {
+	if err := constraints.Validate(); err != nil {
+		return nil, err
+	}
+
+	snapshot, err := s.analysisService.AnalyzeAllStocks(ctx, s.analysisService.DefaultOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	built, err := portfolio.Construct(name, snapshot.Analyses, constraints, snapshot.DataVersion)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.portfolios.SavePortfolio(ctx, built); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "Portfolio constructed", map[string]interface{}{
+		"portfolio_id": built.ID,
+		"holdings":     len(built.Holdings),
+		"cash":         built.Cash,
+	})
+	return built, nil
+}
+
+func (s *PortfolioService) GetPortfolio(ctx context.Context, id uuid.UUID) (*portfolio.Portfolio, error) {
+	return s.portfolios.FindPortfolio(ctx, id)
+}
+
+func (s *PortfolioService) ListPortfolios(ctx context.Context, limit int) ([]portfolio.Portfolio, error) {
+	return s.portfolios.FindPortfolios(ctx, limit)
+}
+
+// UploadHoldings stores positions actually held as a holdings portfolio. The
+// upload is rejected with an *ImportError listing every invalid entry, counted
+// from 1, unless all of them are valid.
+func (s *PortfolioService) UploadHoldings(ctx context.Context, name string, holdings []portfolio.Holding) (*portfolio.Portfolio, error) {
+	seen := make(map[stock.Ticker]int)
+	var invalid []ImportLineError
+	for i := range holdings {
+		if message := checkHolding(&holdings[i], string(holdings[i].Ticker), i+1, seen); message != "" {
+			invalid = append(invalid, ImportLineError{Line: i + 1, Message: message})
+		}
+	}
+	if len(holdings) == 0 {
+		invalid = append(invalid, ImportLineError{Line: 1, Message: "no holdings"})
+	}
+	if len(invalid) > 0 {
+		return nil, &ImportError{Lines: invalid}
+	}
+	return s.saveHoldings(ctx, name, holdings)
+}
+
+// ImportHoldingsCSV stores the positions of a CSV file with a header line as a
+// holdings portfolio, on the same terms as UploadHoldings
+func (s *PortfolioService) ImportHoldingsCSV(ctx context.Context, name string, r io.Reader) (*portfolio.Portfolio, error) {
+	holdings, err := parseHoldings(r)
+	if err != nil {
+		return nil, err
+	}
+	return s.saveHoldings(ctx, name, holdings)
+}
+
+func (s *PortfolioService) saveHoldings(ctx context.Context, name string, holdings []portfolio.Holding) (*portfolio.Portfolio, error) {
+	uploaded := portfolio.NewHoldings(name, holdings)
+	if err := s.portfolios.SavePortfolio(ctx, uploaded); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "Holdings uploaded", map[string]interface{}{
+		"portfolio_id": uploaded.ID,
+		"holdings":     len(uploaded.Holdings),
+	})
+	return uploaded, nil
+}
+
+func parseHoldings(r io.Reader) ([]portfolio.Holding, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	index, err := readHeader(reader, holdingColumns, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	var holdings []portfolio.Holding
+	seen := make(map[stock.Ticker]int)
+	invalid, err := readLines(reader, index, func(line int, field func(string) string) string {
+		var h portfolio.Holding
+
+		quantity, err := strconv.ParseFloat(field("quantity"), 64)
+		if err != nil {
+			return fmt.Sprintf("invalid quantity %q", field("quantity"))
+		}
+		h.Quantity = quantity
+		if value := field("cost_basis"); value != "" {
+			costBasis, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Sprintf("invalid cost_basis %q", value)
+			}
+			h.CostBasis = costBasis
+		}
+
+		if message := checkHolding(&h, field("ticker"), line, seen); message != "" {
+			return message
+		}
+		holdings = append(holdings, h)
+		return ""
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading holdings: %w", err)
+	}
+	if len(invalid) == 0 && len(holdings) == 0 {
+		invalid = append(invalid, ImportLineError{Line: 2, Message: "no holdings"})
+	}
+
+	if len(invalid) > 0 {
+		return nil, &ImportError{Lines: invalid}
+	}
+	return holdings, nil
+}
+
+// checkHolding sets the holding's ticker from the symbol and returns why the
+// position cannot be held, or an empty string. Valid tickers are recorded in
+// seen with their line.
+func checkHolding(h *portfolio.Holding, symbol string, line int, seen map[stock.Ticker]int) string {
+	ticker, err := stock.ParseTicker(symbol)
+	h.Ticker = ticker
+	switch {
+	case strings.TrimSpace(symbol) == "":
+		return "ticker is required"
+	case err != nil:
+		return fmt.Sprintf("invalid ticker %q", symbol)
+	case h.Quantity <= 0:
+		return "quantity must be positive"
+	case h.CostBasis < 0:
+		return "cost_basis cannot be negative"
+	case seen[h.Ticker] != 0:
+		return fmt.Sprintf("ticker %s already on line %d", h.Ticker, seen[h.Ticker])
+	}
+	seen[h.Ticker] = line
+	return ""
+}
+
+// Review reports the rating actions on the holdings of a portfolio, flagging
+// the ones top tier brokers turned negative on since the given time
+func (s *PortfolioService) Review(ctx context.Context, id uuid.UUID, since time.Time) (*portfolio.Review, error) {
+	found, err := s.portfolios.FindPortfolio(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	tickers := make([]stock.Ticker, len(found.Holdings))
+	for i, h := range found.Holdings {
+		tickers[i] = h.Ticker
+	}
+	actions, err := s.actions.FindRatingActions(ctx, tickers)
+	if err != nil {
+		return nil, err
+	}
+
+	return portfolio.ReviewHoldings(found, actions, since), nil
+}
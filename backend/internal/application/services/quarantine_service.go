@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"stockapi/internal/domain/shared"
+	"stockapi/internal/domain/stock"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrRecordStillInvalid wraps the parse error of a reprocessed record that is
+// still malformed; the record stays quarantined with the new reason
+var ErrRecordStillInvalid = errors.New("record is still invalid")
+
+// ReprocessResult is the outcome of a successful reprocess. Stock is nil when
+// a newer record for the ticker was already stored, or the ticker is locked by
+// a manual correction, and the reject was only marked as resolved.
+type ReprocessResult struct {
+	Record *stock.RejectedRecord
+	Stock  *stock.Stock
+}
+
+type QuarantineService struct {
+	rejected    stock.RejectedRecordRepository
+	repo        stock.Repository
+	actions     stock.RatingActionRepository
+	corrections stock.CorrectionRepository
+	versions    stock.DataVersionRepository
+	parser      stock.RecordParser
+	logger      shared.Logger
+}
+
+func NewQuarantineService(
+	rejected stock.RejectedRecordRepository,
+	repo stock.Repository,
+	actions stock.RatingActionRepository,
+	corrections stock.CorrectionRepository,
+	versions stock.DataVersionRepository,
+	parser stock.RecordParser,
+	logger shared.Logger,
+) *QuarantineService {
+	return &QuarantineService{
+		rejected:    rejected,
+		repo:        repo,
+		actions:     actions,
+		corrections: corrections,
+		versions:    versions,
+		parser:      parser,
+		logger:      logger,
+	}
+}
+
+func (s *QuarantineService) ListRejected(ctx context.Context, status stock.RejectedStatus, limit int) ([]stock.RejectedRecord, error) {
+	return s.rejected.FindRejected(ctx, status, limit)
+}
+
+// Reprocess parses a pending record again, optionally with a corrected payload,
+// and stores the resulting stock
+func (s *QuarantineService) Reprocess(ctx context.Context, id uuid.UUID, payload []byte) (*ReprocessResult, error) {
+	record, err := s.pending(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) > 0 {
+		record.Payload = payload
+	}
+
+	stk, parseErr := s.parser.ParseRecord(record.Payload)
+	if parseErr != nil {
+		record.Reason = parseErr.Error()
+		if err := s.rejected.UpdateRejected(ctx, record); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", ErrRecordStillInvalid, parseErr)
+	}
+	stk.Source = record.Source
+
+	// Manual corrections stand until released, in the rating history too
+	locked, err := s.tickerLocked(ctx, stk.Ticker)
+	if err != nil {
+		return nil, err
+	}
+	// The action belongs in the rating history even when a newer one is stored
+	if !locked {
+		if err := s.actions.SaveRatingActions(ctx, []*stock.Stock{stk}); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &ReprocessResult{Record: record}
+	stored, err := s.repo.FindByTicker(ctx, stk.Ticker)
+	if err != nil && !errors.Is(err, stock.ErrStockNotFound) {
+		return nil, fmt.Errorf("error loading stored stock: %w", err)
+	}
+	// A quarantined record is older than whatever later syncs stored
+	if !locked && (stored == nil || !stored.Time.After(stk.Time)) {
+		err := s.repo.Save(ctx, stk)
+		switch {
+		case errors.Is(err, stock.ErrStockLocked):
+			// Locked by a correction since the check above
+			locked = true
+		case err != nil:
+			return nil, fmt.Errorf("error saving reprocessed stock: %w", err)
+		default:
+			if _, err := s.versions.BumpDataVersion(ctx); err != nil {
+				return nil, err
+			}
+			result.Stock = stk
+		}
+	}
+
+	if err := s.resolve(ctx, record, stock.RejectedReprocessed); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "Rejected record reprocessed", map[string]interface{}{
+		"id":         record.ID,
+		"ticker":     stk.Ticker,
+		"superseded": result.Stock == nil,
+		"locked":     locked,
+	})
+	return result, nil
+}
+
+// Discard marks a pending record as one that will never be reprocessed
+func (s *QuarantineService) Discard(ctx context.Context, id uuid.UUID) (*stock.RejectedRecord, error) {
+	record, err := s.pending(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.resolve(ctx, record, stock.RejectedDiscarded); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "Rejected record discarded", map[string]interface{}{
+		"id": record.ID,
+	})
+	return record, nil
+}
+
+func (s *QuarantineService) tickerLocked(ctx context.Context, ticker stock.Ticker) (bool, error) {
+	locks, err := s.corrections.FindStockLocks(ctx, uuid.Nil)
+	if err != nil {
+		return false, fmt.Errorf("error loading stock locks: %w", err)
+	}
+	for _, lock := range locks {
+		if lock.Ticker == ticker {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *QuarantineService) pending(ctx context.Context, id uuid.UUID) (*stock.RejectedRecord, error) {
+	record, err := s.rejected.FindRejectedByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if record.Status != stock.RejectedPending {
+		return nil, stock.ErrRecordAlreadyResolved
+	}
+	return record, nil
+}
+
+func (s *QuarantineService) resolve(ctx context.Context, record *stock.RejectedRecord, status stock.RejectedStatus) error {
+	record.Status = status
+	record.ResolvedAt = time.Now()
+	return s.rejected.UpdateRejected(ctx, record)
+}
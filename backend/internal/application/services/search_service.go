@@ -0,0 +1,30 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"stockapi/internal/domain/shared"
+	"stockapi/internal/domain/stock"
+	"strings"
+)
+
+type SearchService struct {
+	repo   stock.SearchRepository
+	logger shared.Logger
+}
+
+func NewSearchService(repo stock.SearchRepository, logger shared.Logger) *SearchService {
+	return &SearchService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Search finds stocks by ticker, company or brokerage, best match first
+func (s *SearchService) Search(ctx context.Context, query string, limit int) ([]stock.SearchHit, error) {
+	hits, err := s.repo.Search(ctx, strings.TrimSpace(query), limit)
+	if err != nil {
+		return nil, fmt.Errorf("error searching stocks: %w", err)
+	}
+	return hits, nil
+}
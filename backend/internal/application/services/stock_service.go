@@ -2,60 +2,602 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"stockapi/internal/domain/shared"
 	"stockapi/internal/domain/stock"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
 )
 
+var ErrShuttingDown = errors.New("service is shutting down")
+
+// SyncOptions controls a single synchronization run
+type SyncOptions struct {
+	// Full ignores the stored checkpoints and crawls the whole provider history
+	Full bool
+}
+
+// SyncSettings tune how syncs are scheduled and coordinated between replicas
+type SyncSettings struct {
+	// FullSyncInterval forces a full crawl when the last one is older than it
+	FullSyncInterval time.Duration
+	// LeaseTTL is how long the sync lease survives without a heartbeat
+	LeaseTTL time.Duration
+	// InstanceID identifies this replica as the lease holder
+	InstanceID string
+}
+
 type StockService struct {
-	repo    stock.Repository
-	apiPort stock.StockAPIPort
-	logger  shared.Logger
+	repo        stock.Repository
+	actions     stock.RatingActionRepository
+	corrections stock.CorrectionRepository
+	syncStates  stock.SyncStateRepository
+	syncRuns    stock.SyncRunRepository
+	rejected    stock.RejectedRecordRepository
+	leases      stock.SyncLeaseRepository
+	versions    stock.DataVersionRepository
+	apiPort     stock.StockAPIPort
+	logger      shared.Logger
+	settings    SyncSettings
+
+	mu         sync.RWMutex
+	lastSyncAt time.Time
+
+	// Running syncs are tracked so shutdown can cancel and await them
+	jobs           sync.WaitGroup
+	shutdownCtx    context.Context
+	cancelShutdown context.CancelFunc
 }
 
-func NewStockService(repo stock.Repository, apiPort stock.StockAPIPort, logger shared.Logger) *StockService {
+func NewStockService(
+	repo stock.Repository,
+	actions stock.RatingActionRepository,
+	corrections stock.CorrectionRepository,
+	syncStates stock.SyncStateRepository,
+	syncRuns stock.SyncRunRepository,
+	rejected stock.RejectedRecordRepository,
+	leases stock.SyncLeaseRepository,
+	versions stock.DataVersionRepository,
+	apiPort stock.StockAPIPort,
+	logger shared.Logger,
+	settings SyncSettings,
+) *StockService {
+	shutdownCtx, cancel := context.WithCancel(context.Background())
 	return &StockService{
-		repo:    repo,
-		apiPort: apiPort,
-		logger:  logger,
+		repo:           repo,
+		actions:        actions,
+		corrections:    corrections,
+		syncStates:     syncStates,
+		syncRuns:       syncRuns,
+		rejected:       rejected,
+		leases:         leases,
+		versions:       versions,
+		apiPort:        apiPort,
+		logger:         logger,
+		settings:       settings,
+		shutdownCtx:    shutdownCtx,
+		cancelShutdown: cancel,
 	}
 }
 
-func (s *StockService) SyncStocksFromAPI(ctx context.Context) error {
-	s.logger.Info(ctx, "Starting stock synchronization from API", nil)
+// SyncStocksFromAPI fetches new events from the providers, classifies each one
+// against the stored record and saves them. Only one sync runs at a time across
+// all replicas; otherwise a *stock.SyncInProgressError is returned. The
+// returned run is recorded even when the sync fails.
+func (s *StockService) SyncStocksFromAPI(ctx context.Context, opts SyncOptions) (*stock.SyncRun, error) {
+	ctx, done, err := s.startJob(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
 
-	stocks, err := s.apiPort.FetchStocks(ctx)
+	run := stock.NewSyncRun(opts.Full)
+	ctx, release, err := s.acquireLease(ctx, run.ID)
 	if err != nil {
-		s.logger.Error(ctx, "Failed to fetch stocks from API", map[string]interface{}{
-			"error": err.Error(),
-		})
-		return fmt.Errorf("error fetching stocks: %w", err)
+		return nil, err
 	}
+	defer release()
 
-	s.logger.Info(ctx, "Successfully fetched stocks from API", map[string]interface{}{
-		"count": len(stocks),
+	states, err := s.syncStates.FindAllSyncStates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error loading sync state: %w", err)
+	}
+	fetchOpts, full := s.fetchOptions(states, opts.Full)
+
+	run.Full = full
+	if err := s.syncRuns.CreateSyncRun(ctx, run); err != nil {
+		return nil, fmt.Errorf("error recording sync run: %w", err)
+	}
+
+	s.logger.Info(ctx, "Starting stock synchronization from API", map[string]interface{}{
+		"run_id": run.ID,
+		"full":   full,
+	})
+
+	changes, syncErr := s.runSync(ctx, run, states, fetchOpts)
+	if cause := context.Cause(ctx); errors.Is(cause, stock.ErrSyncLeaseLost) {
+		syncErr = cause
+	}
+	s.publishChanges(ctx, changes)
+	if err := s.finishRun(ctx, run, changes, syncErr); err != nil && syncErr == nil {
+		return run, err
+	}
+	if syncErr != nil {
+		return run, syncErr
+	}
+
+	s.mu.Lock()
+	s.lastSyncAt = time.Now()
+	s.mu.Unlock()
+
+	s.logger.Info(ctx, "Stock synchronization completed", map[string]interface{}{
+		"run_id":       run.ID,
+		"total_synced": run.Fetched,
+		"new":          run.Summary.New,
+		"changed":      run.Summary.Changed,
+		"unchanged":    run.Summary.Unchanged,
+		"rejected":     run.Rejected,
 	})
+	return run, nil
+}
+
+func (s *StockService) runSync(ctx context.Context, run *stock.SyncRun, states []stock.SyncState, fetchOpts stock.FetchOptions) ([]stock.StockChange, error) {
+	result, err := s.fetch(ctx, fetchOpts)
+	if err != nil {
+		return nil, err
+	}
+	run.Fetched = len(result.Stocks)
+
+	if err := s.quarantine(ctx, run, result.Rejected); err != nil {
+		return nil, err
+	}
 
-	for _, stock := range stocks {
-		if err := s.repo.Save(ctx, stock); err != nil {
+	stored, err := s.storedByTicker(ctx)
+	if err != nil {
+		return nil, err
+	}
+	locked, err := s.lockedTickers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := planChanges(stored, locked, result.Stocks)
+	var unlocked []*stock.Stock
+	for i, stk := range result.Stocks {
+		var err error
+		if changes[i].Kind != stock.ChangeLocked {
+			err = s.repo.Save(ctx, stk)
+		}
+		// A correction can lock the ticker after the plan was made; the save
+		// then skips the record like a planned lock
+		if errors.Is(err, stock.ErrStockLocked) {
+			changes[i] = stock.StockChange{Ticker: stk.Ticker, Kind: stock.ChangeLocked}
+			err = nil
+		}
+		run.Summary.Add(changes[i])
+		if err != nil {
 			s.logger.Error(ctx, "Failed to save stock", map[string]interface{}{
-				"ticker": stock.Ticker,
+				"ticker": stk.Ticker,
 				"error":  err.Error(),
 			})
-			return fmt.Errorf("error saving stock %s: %w", stock.Ticker, err)
+			return changes[:i+1], fmt.Errorf("error saving stock %s: %w", stk.Ticker, err)
+		}
+		if changes[i].Kind == stock.ChangeLocked {
+			continue
 		}
+		unlocked = append(unlocked, stk)
+
 		s.logger.Debug(ctx, "Stock saved successfully", map[string]interface{}{
-			"ticker": stock.Ticker,
-			"id":     stock.ID,
+			"ticker": stk.Ticker,
+			"id":     stk.ID,
+			"change": changes[i].Kind,
 		})
 	}
 
-	s.logger.Info(ctx, "Stock synchronization completed", map[string]interface{}{
-		"total_synced": len(stocks),
+	// Stocks only keep the latest action per ticker, so the full history is
+	// recorded before the checkpoints move past it. Locked tickers keep the
+	// history of their manual correction, which the repository enforces for
+	// locks taken since.
+	if err := s.actions.SaveRatingActions(ctx, unlocked); err != nil {
+		return changes, err
+	}
+
+	if err := s.saveCheckpoints(ctx, states, result.Stocks, run.Full); err != nil {
+		return changes, err
+	}
+	return changes, nil
+}
+
+// acquireLease takes the cluster-wide sync lease and keeps it alive until the
+// returned release is called. Losing the lease cancels the returned context
+// with stock.ErrSyncLeaseLost, since another replica may be syncing by then.
+func (s *StockService) acquireLease(ctx context.Context, runID uuid.UUID) (context.Context, func(), error) {
+	lease := &stock.SyncLease{
+		Name:   stock.SyncLeaseName,
+		Holder: s.settings.InstanceID,
+		RunID:  runID,
+	}
+	if err := s.leases.AcquireLease(ctx, lease, s.settings.LeaseTTL); err != nil {
+		return nil, nil, err
+	}
+
+	leaseCtx, cancel := context.WithCancelCause(ctx)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(s.settings.LeaseTTL / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-leaseCtx.Done():
+				return
+			case <-ticker.C:
+				if err := s.leases.RenewLease(leaseCtx, lease, s.settings.LeaseTTL); err != nil {
+					if leaseCtx.Err() != nil {
+						return
+					}
+					s.logger.Error(leaseCtx, "Failed to renew sync lease", map[string]interface{}{
+						"run_id": runID,
+						"error":  err.Error(),
+					})
+					if errors.Is(err, stock.ErrSyncLeaseLost) {
+						cancel(err)
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	return leaseCtx, func() {
+		cancel(nil)
+		<-stopped
+
+		releaseCtx, cancelRelease := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancelRelease()
+		if err := s.leases.ReleaseLease(releaseCtx, lease); err != nil {
+			s.logger.Error(releaseCtx, "Failed to release sync lease", map[string]interface{}{
+				"run_id": runID,
+				"error":  err.Error(),
+			})
+		}
+	}, nil
+}
+
+// quarantine stores the records the providers sent but could not be parsed, so
+// one bad record does not hold back the rest of the sync
+func (s *StockService) quarantine(ctx context.Context, run *stock.SyncRun, rejected []stock.RejectedRecord) error {
+	if len(rejected) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	reasons := make(map[string]int)
+	for i := range rejected {
+		rejected[i].ID = uuid.New()
+		rejected[i].RunID = run.ID
+		rejected[i].Status = stock.RejectedPending
+		rejected[i].ReceivedAt = now
+		reasons[rejected[i].Reason]++
+	}
+	saved, err := s.rejected.SaveRejected(ctx, rejected)
+	if err != nil {
+		return fmt.Errorf("error quarantining rejected records: %w", err)
+	}
+	run.Rejected = len(rejected)
+
+	s.logger.Warn(ctx, "Quarantined malformed provider records", map[string]interface{}{
+		"run_id":  run.ID,
+		"count":   len(rejected),
+		"new":     saved,
+		"reasons": reasons,
+	})
+	return nil
+}
+
+// DryRunReport describes what a sync would do without persisting anything
+type DryRunReport struct {
+	Full          bool
+	Fetched       int
+	Summary       stock.ChangeSummary
+	Changes       []stock.StockChange
+	ParseFailures []stock.RejectedRecord
+}
+
+// DryRunSync runs the fetch and parsing pipeline and compares the result with
+// the stored records, without saving stocks, checkpoints or a sync run
+func (s *StockService) DryRunSync(ctx context.Context, opts SyncOptions) (*DryRunReport, error) {
+	ctx, done, err := s.startJob(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	states, err := s.syncStates.FindAllSyncStates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error loading sync state: %w", err)
+	}
+	fetchOpts, full := s.fetchOptions(states, opts.Full)
+
+	s.logger.Info(ctx, "Starting dry-run stock synchronization", map[string]interface{}{
+		"full": full,
+	})
+
+	result, err := s.fetch(ctx, fetchOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := s.storedByTicker(ctx)
+	if err != nil {
+		return nil, err
+	}
+	locked, err := s.lockedTickers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &DryRunReport{
+		Full:          full,
+		Fetched:       len(result.Stocks),
+		ParseFailures: result.Rejected,
+	}
+	for _, change := range planChanges(stored, locked, result.Stocks) {
+		report.Summary.Add(change)
+		if change.Kind != stock.ChangeUnchanged {
+			report.Changes = append(report.Changes, change)
+		}
+	}
+	return report, nil
+}
+
+func (s *StockService) fetch(ctx context.Context, fetchOpts stock.FetchOptions) (*stock.FetchResult, error) {
+	result, err := s.apiPort.FetchStocks(ctx, fetchOpts)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to fetch stocks from API", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil, fmt.Errorf("error fetching stocks: %w", err)
+	}
+
+	s.logger.Info(ctx, "Successfully fetched stocks from API", map[string]interface{}{
+		"count":    len(result.Stocks),
+		"rejected": len(result.Rejected),
+	})
+	return result, nil
+}
+
+// planChanges classifies the incoming stocks in order. Each one becomes the
+// stored record for the next with the same ticker, as it would once saved,
+// except on locked tickers, which are left as they are.
+func planChanges(stored map[stock.Ticker]*stock.Stock, locked map[stock.Ticker]bool, stocks []*stock.Stock) []stock.StockChange {
+	current := make(map[stock.Ticker]*stock.Stock, len(stored))
+	for ticker, stk := range stored {
+		current[ticker] = stk
+	}
+
+	changes := make([]stock.StockChange, len(stocks))
+	for i, stk := range stocks {
+		if locked[stk.Ticker] {
+			changes[i] = stock.StockChange{Ticker: stk.Ticker, Kind: stock.ChangeLocked}
+			continue
+		}
+		changes[i] = stock.DiffStock(current[stk.Ticker], stk)
+		current[stk.Ticker] = stk
+	}
+	return changes
+}
+
+// lockedTickers loads the tickers corrected by hand, which syncs leave alone
+// until an admin releases them
+func (s *StockService) lockedTickers(ctx context.Context) (map[stock.Ticker]bool, error) {
+	locks, err := s.corrections.FindStockLocks(ctx, uuid.Nil)
+	if err != nil {
+		return nil, fmt.Errorf("error loading stock locks: %w", err)
+	}
+	locked := make(map[stock.Ticker]bool, len(locks))
+	for _, lock := range locks {
+		locked[lock.Ticker] = true
+	}
+	return locked, nil
+}
+
+// storedByTicker loads the current record of every ticker to diff against
+func (s *StockService) storedByTicker(ctx context.Context) (map[stock.Ticker]*stock.Stock, error) {
+	stored := make(map[stock.Ticker]*stock.Stock)
+	err := s.repo.Iterate(ctx, func(stk *stock.Stock) error {
+		// Rows come newest first, so the first one seen is the current record
+		if _, ok := stored[stk.Ticker]; !ok {
+			stored[stk.Ticker] = stk
+		}
+		return nil
 	})
+	if err != nil {
+		return nil, fmt.Errorf("error loading stored stocks: %w", err)
+	}
+	return stored, nil
+}
+
+// finishRun records the outcome of the run. It uses a fresh context so a
+// cancelled sync is still marked as failed.
+func (s *StockService) finishRun(ctx context.Context, run *stock.SyncRun, changes []stock.StockChange, syncErr error) error {
+	run.FinishedAt = time.Now()
+	run.Status = stock.SyncSucceeded
+	if syncErr != nil {
+		run.Status = stock.SyncFailed
+		run.Error = syncErr.Error()
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+
+	if err := s.syncRuns.FinishSyncRun(ctx, run, changes); err != nil {
+		s.logger.Error(ctx, "Failed to record sync run", map[string]interface{}{
+			"run_id": run.ID,
+			"error":  err.Error(),
+		})
+		return fmt.Errorf("error recording sync run: %w", err)
+	}
 	return nil
 }
 
+// publishChanges bumps the data version when the run wrote anything, which
+// invalidates analyses cached from the previous data
+func (s *StockService) publishChanges(ctx context.Context, changes []stock.StockChange) {
+	written := false
+	for _, change := range changes {
+		if change.Kind == stock.ChangeNew || change.Kind == stock.ChangeUpdated {
+			written = true
+			break
+		}
+	}
+	if !written {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+
+	if _, err := s.versions.BumpDataVersion(ctx); err != nil {
+		s.logger.Error(ctx, "Failed to bump data version", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
+// DataVersion returns the version of the stored stock data
+func (s *StockService) DataVersion(ctx context.Context) (stock.DataVersion, error) {
+	return s.versions.CurrentDataVersion(ctx)
+}
+
+// GetSyncRunChanges returns a sync run with the records it created or changed
+func (s *StockService) GetSyncRunChanges(ctx context.Context, id uuid.UUID) (*stock.SyncRun, []stock.StockChange, error) {
+	run, err := s.syncRuns.FindSyncRun(ctx, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error fetching sync run: %w", err)
+	}
+
+	changes, err := s.syncRuns.FindSyncChanges(ctx, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error fetching sync changes: %w", err)
+	}
+	return run, changes, nil
+}
+
+// fetchOptions turns the stored checkpoints into per-source high-water marks.
+// A full crawl is done when requested, on the first sync, or when the last
+// full crawl is older than the configured interval.
+func (s *StockService) fetchOptions(states []stock.SyncState, requestedFull bool) (stock.FetchOptions, bool) {
+	if requestedFull || len(states) == 0 {
+		return stock.FetchOptions{}, true
+	}
+
+	opts := stock.FetchOptions{SinceBySource: make(map[string]time.Time, len(states))}
+	for _, state := range states {
+		if s.settings.FullSyncInterval > 0 && time.Since(state.LastFullSyncAt) > s.settings.FullSyncInterval {
+			return stock.FetchOptions{}, true
+		}
+		if !state.LastEventTime.IsZero() {
+			opts.SinceBySource[state.Source] = state.LastEventTime
+		}
+	}
+	return opts, false
+}
+
+// saveCheckpoints advances the high-water mark of every source that reported
+// newer events, and stamps all sources after a full crawl
+func (s *StockService) saveCheckpoints(ctx context.Context, states []stock.SyncState, stocks []*stock.Stock, full bool) error {
+	bySource := make(map[string]stock.SyncState, len(states))
+	for _, state := range states {
+		bySource[state.Source] = state
+	}
+
+	changed := make(map[string]bool)
+	for _, stk := range stocks {
+		state := bySource[stk.Source]
+		state.Source = stk.Source
+		if stk.Time.After(state.LastEventTime) {
+			state.LastEventTime = stk.Time
+			changed[stk.Source] = true
+		}
+		bySource[stk.Source] = state
+	}
+
+	now := time.Now()
+	for source, state := range bySource {
+		if full {
+			state.LastFullSyncAt = now
+		} else if !changed[source] {
+			continue
+		}
+
+		if err := s.syncStates.SaveSyncState(ctx, state); err != nil {
+			s.logger.Error(ctx, "Failed to save sync checkpoint", map[string]interface{}{
+				"source": source,
+				"error":  err.Error(),
+			})
+			return fmt.Errorf("error saving sync checkpoint for %q: %w", source, err)
+		}
+	}
+	return nil
+}
+
+// CancelSyncs stops accepting new syncs and cancels the running ones
+func (s *StockService) CancelSyncs() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cancelShutdown()
+}
+
+// Shutdown cancels running syncs and waits for them to return or for ctx to expire
+func (s *StockService) Shutdown(ctx context.Context) error {
+	s.CancelSyncs()
+
+	finished := make(chan struct{})
+	go func() {
+		s.jobs.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("waiting for running syncs: %w", ctx.Err())
+	}
+}
+
+// startJob registers a sync and derives a context that is also cancelled on shutdown
+func (s *StockService) startJob(ctx context.Context) (context.Context, func(), error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.shutdownCtx.Err() != nil {
+		return nil, nil, ErrShuttingDown
+	}
+	s.jobs.Add(1)
+
+	jobCtx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(s.shutdownCtx, cancel)
+	return jobCtx, func() {
+		stop()
+		cancel()
+		s.jobs.Done()
+	}, nil
+}
+
+// LastSuccessfulSync returns the completion time of the last sync, or the zero
+// time if no sync has succeeded since the process started
+func (s *StockService) LastSuccessfulSync() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lastSyncAt
+}
+
 func (s *StockService) GetAllStocks(ctx context.Context) ([]*stock.Stock, error) {
 	stocks, err := s.repo.FindAll(ctx)
 	if err != nil {
@@ -64,8 +606,15 @@ func (s *StockService) GetAllStocks(ctx context.Context) ([]*stock.Stock, error)
 	return stocks, nil
 }
 
-func (s *StockService) GetStockBySymbol(ctx context.Context, symbol string) (*stock.Stock, error) {
-	stock, err := s.repo.FindByTicker(ctx, symbol)
+func (s *StockService) StreamStocks(ctx context.Context, fn func(*stock.Stock) error) error {
+	if err := s.repo.Iterate(ctx, fn); err != nil {
+		return fmt.Errorf("error streaming stocks: %w", err)
+	}
+	return nil
+}
+
+func (s *StockService) GetStockBySymbol(ctx context.Context, ticker stock.Ticker) (*stock.Stock, error) {
+	stock, err := s.repo.FindByTicker(ctx, ticker)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching stock by symbol: %w", err)
 	}
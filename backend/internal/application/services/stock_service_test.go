@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"stockapi/internal/domain/stock"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// lockStore holds the stock locks shared by the fakes, like the stock_locks
+// table the repositories check
+type lockStore struct {
+	locked map[stock.Ticker]bool
+	// pending are locked right after FindStockLocks returns, as if a
+	// correction committed between planning and saving
+	pending []stock.Ticker
+}
+
+type lockingStocks struct {
+	stock.Repository
+	locks *lockStore
+	saved map[stock.Ticker]*stock.Stock
+}
+
+func (r *lockingStocks) Iterate(ctx context.Context, fn func(*stock.Stock) error) error {
+	return nil
+}
+
+func (r *lockingStocks) Save(ctx context.Context, stk *stock.Stock) error {
+	if r.locks.locked[stk.Ticker] {
+		return stock.ErrStockLocked
+	}
+	r.saved[stk.Ticker] = stk
+	return nil
+}
+
+type lockingCorrections struct {
+	stock.CorrectionRepository
+	locks *lockStore
+}
+
+func (c *lockingCorrections) FindStockLocks(ctx context.Context, stockID uuid.UUID) ([]stock.StockLock, error) {
+	var locks []stock.StockLock
+	for ticker := range c.locks.locked {
+		locks = append(locks, stock.StockLock{Ticker: ticker})
+	}
+	for _, ticker := range c.locks.pending {
+		c.locks.locked[ticker] = true
+	}
+	c.locks.pending = nil
+	return locks, nil
+}
+
+type recordedActions struct {
+	stock.RatingActionRepository
+	saved []*stock.Stock
+}
+
+func (a *recordedActions) SaveRatingActions(ctx context.Context, actions []*stock.Stock) error {
+	a.saved = append(a.saved, actions...)
+	return nil
+}
+
+type fetchedStocks struct {
+	stocks []*stock.Stock
+}
+
+func (p fetchedStocks) FetchStocks(ctx context.Context, opts stock.FetchOptions) (*stock.FetchResult, error) {
+	return &stock.FetchResult{Stocks: p.stocks}, nil
+}
+
+type discardSyncStates struct {
+	stock.SyncStateRepository
+}
+
+func (discardSyncStates) SaveSyncState(ctx context.Context, state stock.SyncState) error {
+	return nil
+}
+
+func TestRunSyncSkipsLockedTickers(t *testing.T) {
+	tests := []struct {
+		name    string
+		locked  []stock.Ticker
+		pending []stock.Ticker
+	}{
+		{name: "locked before planning", locked: []stock.Ticker{"BBB"}},
+		{name: "locked between planning and saving", pending: []stock.Ticker{"BBB"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locks := &lockStore{locked: make(map[stock.Ticker]bool), pending: tt.pending}
+			for _, ticker := range tt.locked {
+				locks.locked[ticker] = true
+			}
+			repo := &lockingStocks{locks: locks, saved: make(map[stock.Ticker]*stock.Stock)}
+			actions := &recordedActions{}
+			now := time.Now()
+			fetched := fetchedStocks{stocks: []*stock.Stock{
+				{ID: uuid.New(), Ticker: "AAA", Brokerage: "Acme", Rating: stock.RatingChange{From: stock.Hold, To: stock.Buy}, Time: now, Source: "test"},
+				{ID: uuid.New(), Ticker: "BBB", Brokerage: "Acme", Rating: stock.RatingChange{From: stock.Hold, To: stock.Buy}, Time: now, Source: "test"},
+			}}
+			service := NewStockService(
+				repo, actions, &lockingCorrections{locks: locks},
+				discardSyncStates{}, nil, nil, nil, nil, fetched, discardLogger{}, SyncSettings{},
+			)
+
+			run := stock.NewSyncRun(false)
+			changes, err := service.runSync(context.Background(), run, nil, stock.FetchOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(changes) != 2 || changes[0].Kind != stock.ChangeNew || changes[1].Kind != stock.ChangeLocked {
+				t.Fatalf("changes = %+v, want AAA new and BBB locked", changes)
+			}
+			if run.Summary.New != 1 || run.Summary.Locked != 1 {
+				t.Errorf("summary = %+v, want 1 new and 1 locked", run.Summary)
+			}
+			if _, ok := repo.saved["BBB"]; ok || len(repo.saved) != 1 {
+				t.Errorf("saved stocks = %v, want only AAA", repo.saved)
+			}
+			if len(actions.saved) != 1 || actions.saved[0].Ticker != "AAA" {
+				t.Errorf("saved rating actions = %d, want only AAA", len(actions.saved))
+			}
+		})
+	}
+}
@@ -0,0 +1,279 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+	"stockapi/internal/domain/stock"
+	"time"
+)
+
+type AnomalyKind string
+
+const (
+	// AnomalyTargetOutlier is a target change far outside the usual changes
+	// of the broker or of the ticker
+	AnomalyTargetOutlier AnomalyKind = "target_outlier"
+	// AnomalyDowngradeCluster is a burst of downgrades on one ticker
+	AnomalyDowngradeCluster AnomalyKind = "downgrade_cluster"
+	// AnomalyRatingJump is a rating change of more than one level, which the
+	// analysis rejects as an invalid transition
+	AnomalyRatingJump AnomalyKind = "rating_jump"
+)
+
+// AnomalyKinds lists the detected kinds, in display order
+var AnomalyKinds = []AnomalyKind{
+	AnomalyTargetOutlier,
+	AnomalyDowngradeCluster,
+	AnomalyRatingJump,
+}
+
+// Valid reports whether the kind is one of AnomalyKinds
+func (k AnomalyKind) Valid() bool {
+	for _, kind := range AnomalyKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// AnomalyOptions tune how unusual an event must be to be reported
+type AnomalyOptions struct {
+	// ZThreshold is the z-score a target change must reach against the
+	// changes of its broker or ticker
+	ZThreshold float64
+	// MinSamples is the number of other changes a broker or ticker needs
+	// before its distribution is trusted
+	MinSamples int
+	// ClusterSize downgrades of a ticker within ClusterWindow make a cluster
+	ClusterSize   int
+	ClusterWindow time.Duration
+	// Baseline is how much history before the reported events shapes the
+	// distributions
+	Baseline time.Duration
+}
+
+func DefaultAnomalyOptions() AnomalyOptions {
+	return AnomalyOptions{
+		ZThreshold:    3,
+		MinSamples:    5,
+		ClusterSize:   3,
+		ClusterWindow: 7 * 24 * time.Hour,
+		Baseline:      365 * 24 * time.Hour,
+	}
+}
+
+// Baselines a target change is compared with
+const (
+	BaselineBroker = "broker"
+	BaselineTicker = "ticker"
+)
+
+// Anomaly is an unusual rating event, or a group of them for clusters
+type Anomaly struct {
+	Kind   AnomalyKind
+	Ticker stock.Ticker
+	// Brokerage is empty for downgrade clusters, which span brokers
+	Brokerage string
+	// Time is the time of the event, or of the last downgrade of a cluster
+	Time time.Time
+	// Actions are the rating actions involved, oldest first
+	Actions []*stock.Stock
+
+	// TargetChange, Baseline, BaselineMean, BaselineStdDev and ZScore are set
+	// on target outliers
+	TargetChange   float64
+	Baseline       string
+	BaselineMean   float64
+	BaselineStdDev float64
+	ZScore         float64
+
+	// Levels is the rating level difference of a rating jump
+	Levels int
+}
+
+// distribution accumulates target changes so the mean and standard deviation
+// can be taken with any single change left out
+type distribution struct {
+	n          int
+	sum, sumSq float64
+}
+
+func (d *distribution) add(x float64) {
+	d.n++
+	d.sum += x
+	d.sumSq += x * x
+}
+
+// without returns the sample size, mean and standard deviation of the other
+// changes
+func (d distribution) without(x float64) (int, float64, float64) {
+	n := d.n - 1
+	if n < 2 {
+		return n, 0, 0
+	}
+	sum := d.sum - x
+	mean := sum / float64(n)
+	variance := (d.sumSq - x*x - sum*mean) / float64(n-1)
+	if variance <= 0 {
+		return n, mean, 0
+	}
+	return n, mean, math.Sqrt(variance)
+}
+
+// targetChange is the relative move of the price target
+func targetChange(a *stock.Stock) (float64, bool) {
+	if a.Target.From.Amount <= 0 || a.Target.To.Amount <= 0 {
+		return 0, false
+	}
+	return a.Target.To.Amount/a.Target.From.Amount - 1, true
+}
+
+// DetectAnomalies finds the unusual events among the rating actions. All the
+// actions shape the brokers' and tickers' distributions, but only events from
+// since on are reported, newest first.
+func DetectAnomalies(actions []*stock.Stock, since time.Time, opts AnomalyOptions) []Anomaly {
+	ordered := make([]*stock.Stock, len(actions))
+	copy(ordered, actions)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Time.Before(ordered[j].Time)
+	})
+
+	var anomalies []Anomaly
+	anomalies = append(anomalies, detectTargetOutliers(ordered, since, opts)...)
+	anomalies = append(anomalies, detectDowngradeClusters(ordered, since, opts)...)
+	anomalies = append(anomalies, detectRatingJumps(ordered, since)...)
+
+	sort.SliceStable(anomalies, func(i, j int) bool {
+		return anomalies[i].Time.After(anomalies[j].Time)
+	})
+	return anomalies
+}
+
+func detectTargetOutliers(actions []*stock.Stock, since time.Time, opts AnomalyOptions) []Anomaly {
+	byBroker := make(map[string]*distribution)
+	byTicker := make(map[stock.Ticker]*distribution)
+	for _, a := range actions {
+		change, ok := targetChange(a)
+		if !ok {
+			continue
+		}
+		if byBroker[a.Brokerage] == nil {
+			byBroker[a.Brokerage] = &distribution{}
+		}
+		if byTicker[a.Ticker] == nil {
+			byTicker[a.Ticker] = &distribution{}
+		}
+		byBroker[a.Brokerage].add(change)
+		byTicker[a.Ticker].add(change)
+	}
+
+	var anomalies []Anomaly
+	for _, a := range actions {
+		change, ok := targetChange(a)
+		if !ok || a.Time.Before(since) {
+			continue
+		}
+
+		var outlier *Anomaly
+		for _, baseline := range []struct {
+			name string
+			dist *distribution
+		}{
+			{BaselineBroker, byBroker[a.Brokerage]},
+			{BaselineTicker, byTicker[a.Ticker]},
+		} {
+			n, mean, stdDev := baseline.dist.without(change)
+			if n < opts.MinSamples || stdDev == 0 {
+				continue
+			}
+			z := (change - mean) / stdDev
+			if math.Abs(z) < opts.ZThreshold {
+				continue
+			}
+			if outlier != nil && math.Abs(z) <= math.Abs(outlier.ZScore) {
+				continue
+			}
+			outlier = &Anomaly{
+				Kind:           AnomalyTargetOutlier,
+				Ticker:         a.Ticker,
+				Brokerage:      a.Brokerage,
+				Time:           a.Time,
+				Actions:        []*stock.Stock{a},
+				TargetChange:   change,
+				Baseline:       baseline.name,
+				BaselineMean:   mean,
+				BaselineStdDev: stdDev,
+				ZScore:         z,
+			}
+		}
+		if outlier != nil {
+			anomalies = append(anomalies, *outlier)
+		}
+	}
+	return anomalies
+}
+
+// detectDowngradeClusters groups each ticker's downgrades into runs of at
+// least ClusterSize within ClusterWindow of the first one. A run ending before
+// since is not reported.
+func detectDowngradeClusters(actions []*stock.Stock, since time.Time, opts AnomalyOptions) []Anomaly {
+	downgrades := make(map[stock.Ticker][]*stock.Stock)
+	var tickers []stock.Ticker
+	for _, a := range actions {
+		if a.Rating.Direction() >= 0 {
+			continue
+		}
+		if downgrades[a.Ticker] == nil {
+			tickers = append(tickers, a.Ticker)
+		}
+		downgrades[a.Ticker] = append(downgrades[a.Ticker], a)
+	}
+
+	var anomalies []Anomaly
+	for _, ticker := range tickers {
+		events := downgrades[ticker]
+		for i := 0; i < len(events); {
+			j := i + 1
+			for j < len(events) && events[j].Time.Sub(events[i].Time) <= opts.ClusterWindow {
+				j++
+			}
+			if j-i < opts.ClusterSize {
+				i++
+				continue
+			}
+
+			cluster := events[i:j]
+			last := cluster[len(cluster)-1]
+			if !last.Time.Before(since) {
+				anomalies = append(anomalies, Anomaly{
+					Kind:    AnomalyDowngradeCluster,
+					Ticker:  ticker,
+					Time:    last.Time,
+					Actions: cluster,
+				})
+			}
+			i = j
+		}
+	}
+	return anomalies
+}
+
+func detectRatingJumps(actions []*stock.Stock, since time.Time) []Anomaly {
+	var anomalies []Anomaly
+	for _, a := range actions {
+		levels := a.Rating.Direction()
+		if abs(levels) < 2 || a.Time.Before(since) {
+			continue
+		}
+		anomalies = append(anomalies, Anomaly{
+			Kind:      AnomalyRatingJump,
+			Ticker:    a.Ticker,
+			Brokerage: a.Brokerage,
+			Time:      a.Time,
+			Actions:   []*stock.Stock{a},
+			Levels:    levels,
+		})
+	}
+	return anomalies
+}
@@ -0,0 +1,204 @@
+package analysis
+
+import (
+	"math"
+	"stockapi/internal/domain/stock"
+	"testing"
+	"time"
+)
+
+var anomalyEpoch = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func ratingAction(ticker, brokerage string, day float64, targetFrom, targetTo float64, from, to stock.Rating) *stock.Stock {
+	return &stock.Stock{
+		Ticker:    stock.Ticker(ticker),
+		Brokerage: brokerage,
+		Target: stock.TargetPrice{
+			From: stock.Money{Amount: targetFrom, Currency: "USD"},
+			To:   stock.Money{Amount: targetTo, Currency: "USD"},
+		},
+		Rating: stock.RatingChange{From: from, To: to},
+		Time:   anomalyEpoch.Add(time.Duration(day * float64(24*time.Hour))),
+	}
+}
+
+func TestDistributionWithout(t *testing.T) {
+	tests := []struct {
+		name       string
+		values     []float64
+		without    float64
+		wantN      int
+		wantMean   float64
+		wantStdDev float64
+	}{
+		{"sample of three", []float64{1, 2, 3, 4}, 4, 3, 2, 1},
+		{"left out value in the middle", []float64{2, 4, 10, 6}, 10, 3, 4, 2},
+		{"identical values", []float64{5, 5, 5}, 5, 2, 5, 0},
+		{"too few to spread", []float64{1, 2}, 2, 1, 0, 0},
+		{"only the left out value", []float64{3}, 3, 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var d distribution
+			for _, v := range tt.values {
+				d.add(v)
+			}
+
+			n, mean, stdDev := d.without(tt.without)
+			if n != tt.wantN {
+				t.Errorf("n = %d, want %d", n, tt.wantN)
+			}
+			if math.Abs(mean-tt.wantMean) > 1e-9 {
+				t.Errorf("mean = %v, want %v", mean, tt.wantMean)
+			}
+			if math.Abs(stdDev-tt.wantStdDev) > 1e-9 {
+				t.Errorf("stdDev = %v, want %v", stdDev, tt.wantStdDev)
+			}
+		})
+	}
+}
+
+func TestDetectTargetOutliers(t *testing.T) {
+	// Five usual changes of the broker: mean 2%, standard deviation 1%
+	usual := func() []*stock.Stock {
+		var actions []*stock.Stock
+		for i, pct := range []float64{1, 2, 3, 1, 3} {
+			actions = append(actions, ratingAction("U"+string(rune('A'+i)), "Acme", float64(i), 100, 100+pct, stock.Buy, stock.Buy))
+		}
+		return actions
+	}
+
+	tests := []struct {
+		name       string
+		change     float64
+		day        float64
+		minSamples int
+		wantZ      float64
+		wantFound  bool
+	}{
+		{"far above the broker's changes", 8, 10, 5, 6, true},
+		{"far below the broker's changes", -4, 10, 5, -6, true},
+		{"just over the threshold", 5.5, 10, 5, 3.5, true},
+		{"within the threshold", 4, 10, 5, 0, false},
+		{"before since", 8, 4.5, 5, 0, false},
+		{"too few samples", 8, 10, 6, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actions := append(usual(), ratingAction("OUT", "Acme", tt.day, 100, 100+tt.change, stock.Buy, stock.Buy))
+			opts := DefaultAnomalyOptions()
+			opts.MinSamples = tt.minSamples
+
+			anomalies := detectTargetOutliers(actions, anomalyEpoch.Add(5*24*time.Hour), opts)
+
+			var found *Anomaly
+			for i := range anomalies {
+				if anomalies[i].Ticker == "OUT" {
+					found = &anomalies[i]
+				}
+			}
+			if (found != nil) != tt.wantFound {
+				t.Fatalf("found = %t, want %t (anomalies: %+v)", found != nil, tt.wantFound, anomalies)
+			}
+			if found == nil {
+				return
+			}
+			if found.Baseline != BaselineBroker {
+				t.Errorf("baseline = %q, want %q", found.Baseline, BaselineBroker)
+			}
+			if math.Abs(found.ZScore-tt.wantZ) > 1e-6 {
+				t.Errorf("z-score = %v, want %v", found.ZScore, tt.wantZ)
+			}
+			if math.Abs(found.BaselineMean-0.02) > 1e-9 || math.Abs(found.BaselineStdDev-0.01) > 1e-9 {
+				t.Errorf("baseline = %v ± %v, want 0.02 ± 0.01", found.BaselineMean, found.BaselineStdDev)
+			}
+		})
+	}
+}
+
+func TestDetectDowngradeClusters(t *testing.T) {
+	downgrade := func(day float64) *stock.Stock {
+		return ratingAction("ACME", "Broker", day, 100, 90, stock.Buy, stock.Hold)
+	}
+
+	tests := []struct {
+		name      string
+		actions   []*stock.Stock
+		sinceDay  float64
+		wantSizes []int
+	}{
+		{
+			name:      "three downgrades within the window",
+			actions:   []*stock.Stock{downgrade(0), downgrade(2), downgrade(5)},
+			wantSizes: []int{3},
+		},
+		{
+			name:      "last downgrade exactly at the window",
+			actions:   []*stock.Stock{downgrade(0), downgrade(3), downgrade(7)},
+			wantSizes: []int{3},
+		},
+		{
+			name:    "spread over more than the window",
+			actions: []*stock.Stock{downgrade(0), downgrade(4), downgrade(7.5)},
+		},
+		{
+			name:      "window slides past an early downgrade",
+			actions:   []*stock.Stock{downgrade(0), downgrade(6), downgrade(8), downgrade(10)},
+			wantSizes: []int{3},
+		},
+		{
+			name:      "two separate bursts",
+			actions:   []*stock.Stock{downgrade(0), downgrade(1), downgrade(2), downgrade(20), downgrade(21), downgrade(22), downgrade(23)},
+			wantSizes: []int{3, 4},
+		},
+		{
+			name:     "cluster ending before since",
+			actions:  []*stock.Stock{downgrade(0), downgrade(1), downgrade(2)},
+			sinceDay: 3,
+		},
+		{
+			name: "upgrades and unknown ratings are not downgrades",
+			actions: []*stock.Stock{
+				downgrade(0),
+				ratingAction("ACME", "Broker", 1, 100, 110, stock.Hold, stock.Buy),
+				ratingAction("ACME", "Broker", 2, 100, 90, "Unknown", stock.Sell),
+				downgrade(3),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			since := anomalyEpoch.Add(time.Duration(tt.sinceDay * float64(24*time.Hour)))
+			anomalies := detectDowngradeClusters(tt.actions, since, DefaultAnomalyOptions())
+
+			if len(anomalies) != len(tt.wantSizes) {
+				t.Fatalf("clusters = %d, want %d", len(anomalies), len(tt.wantSizes))
+			}
+			for i, anomaly := range anomalies {
+				if len(anomaly.Actions) != tt.wantSizes[i] {
+					t.Errorf("cluster %d size = %d, want %d", i, len(anomaly.Actions), tt.wantSizes[i])
+				}
+				last := anomaly.Actions[len(anomaly.Actions)-1]
+				if !anomaly.Time.Equal(last.Time) {
+					t.Errorf("cluster %d time = %s, want its last downgrade %s", i, anomaly.Time, last.Time)
+				}
+			}
+		})
+	}
+}
+
+func TestAnomalyKindValid(t *testing.T) {
+	for _, kind := range AnomalyKinds {
+		if !kind.Valid() {
+			t.Errorf("%q is not valid", kind)
+		}
+	}
+	for _, kind := range []AnomalyKind{"", "rating-jump", "TARGET_OUTLIER"} {
+		if kind.Valid() {
+			t.Errorf("%q is valid", kind)
+		}
+	}
+}
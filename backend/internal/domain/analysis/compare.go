@@ -0,0 +1,93 @@
+package analysis
+
+import (
+	"stockapi/internal/domain/stock"
+	"time"
+)
+
+// LatestByBroker keeps the latest action of each broker, newest first, from
+// actions given newest first
+func LatestByBroker(actions []*stock.Stock) []*stock.Stock {
+	seen := make(map[string]bool)
+	var latest []*stock.Stock
+	for _, a := range actions {
+		if seen[a.Brokerage] {
+			continue
+		}
+		seen[a.Brokerage] = true
+		latest = append(latest, a)
+	}
+	return latest
+}
+
+// Consensus summarizes the latest rating of each broker on a ticker. Ratings
+// of level 3 and 4 count as positive, 2 as neutral and 1 as negative; brokers
+// with an unknown rating are only counted in Brokers.
+type Consensus struct {
+	Brokers  int
+	Positive int
+	Neutral  int
+	Negative int
+	// AverageTarget is the mean price target of the brokers that set one
+	AverageTarget float64
+}
+
+func ConsensusOf(latest []*stock.Stock) Consensus {
+	consensus := Consensus{Brokers: len(latest)}
+	var targets float64
+	var targeted int
+	for _, a := range latest {
+		switch level := a.Rating.To.Level(); {
+		case level >= 3:
+			consensus.Positive++
+		case level == 2:
+			consensus.Neutral++
+		case level == 1:
+			consensus.Negative++
+		}
+		if a.Target.To.Amount > 0 {
+			targets += a.Target.To.Amount
+			targeted++
+		}
+	}
+	if targeted > 0 {
+		consensus.AverageTarget = targets / float64(targeted)
+	}
+	return consensus
+}
+
+// Timeline lays score histories on common days
+type Timeline struct {
+	// Days are the UTC dates from the first to the last day of the range
+	Days []time.Time
+	// Scores holds, per ticker, the last score recorded by the end of each
+	// day, carried over days without a run; nil before the first score
+	Scores map[stock.Ticker][]*float64
+}
+
+// AlignScores builds the timeline of the histories, given oldest first, over
+// the days between from and to
+func AlignScores(histories map[stock.Ticker][]ScorePoint, from, to time.Time) Timeline {
+	first := from.UTC().Truncate(24 * time.Hour)
+	timeline := Timeline{Scores: make(map[stock.Ticker][]*float64, len(histories))}
+	for day := first; !day.After(to); day = day.AddDate(0, 0, 1) {
+		timeline.Days = append(timeline.Days, day)
+	}
+
+	for ticker, points := range histories {
+		scores := make([]*float64, len(timeline.Days))
+		next := 0
+		var last *float64
+		for i, day := range timeline.Days {
+			end := day.AddDate(0, 0, 1)
+			for next < len(points) && points[next].AnalyzedAt.Before(end) {
+				score := points[next].Score
+				last = &score
+				next++
+			}
+			scores[i] = last
+		}
+		timeline.Scores[ticker] = scores
+	}
+	return timeline
+}
@@ -0,0 +1,104 @@
+package analysis
+
+import (
+	"stockapi/internal/domain/stock"
+	"testing"
+	"time"
+)
+
+func TestAlignScores(t *testing.T) {
+	at := func(day, hour int) time.Time {
+		return time.Date(2025, 1, day, hour, 0, 0, 0, time.UTC)
+	}
+	point := func(analyzedAt time.Time, score float64) ScorePoint {
+		return ScorePoint{Score: score, AnalyzedAt: analyzedAt}
+	}
+	plus5 := time.FixedZone("UTC+5", 5*60*60)
+
+	tests := []struct {
+		name     string
+		points   []ScorePoint
+		from, to time.Time
+		wantDays []time.Time
+		want     []any
+	}{
+		{
+			name:     "last score of each day",
+			points:   []ScorePoint{point(at(1, 12), 0.5), point(at(1, 20), 0.6), point(at(2, 8), 0.7)},
+			from:     at(1, 10),
+			to:       at(2, 23),
+			wantDays: []time.Time{at(1, 0), at(2, 0)},
+			want:     []any{0.6, 0.7},
+		},
+		{
+			name:     "carried over days without a run",
+			points:   []ScorePoint{point(at(1, 12), 0.5), point(at(4, 0), 0.8)},
+			from:     at(1, 0),
+			to:       at(4, 5),
+			wantDays: []time.Time{at(1, 0), at(2, 0), at(3, 0), at(4, 0)},
+			want:     []any{0.5, 0.5, 0.5, 0.8},
+		},
+		{
+			name:     "nil before the first score",
+			points:   []ScorePoint{point(at(3, 9), 0.4)},
+			from:     at(1, 0),
+			to:       at(3, 12),
+			wantDays: []time.Time{at(1, 0), at(2, 0), at(3, 0)},
+			want:     []any{nil, nil, 0.4},
+		},
+		{
+			name:     "scores before the range seed the first day",
+			points:   []ScorePoint{point(at(1, 6), 0.3)},
+			from:     at(2, 0),
+			to:       at(3, 0),
+			wantDays: []time.Time{at(2, 0), at(3, 0)},
+			want:     []any{0.3, 0.3},
+		},
+		{
+			name:     "no scores",
+			from:     at(1, 0),
+			to:       at(2, 0),
+			wantDays: []time.Time{at(1, 0), at(2, 0)},
+			want:     []any{nil, nil},
+		},
+		{
+			name:     "days are UTC dates",
+			points:   []ScorePoint{point(at(1, 23), 0.9)},
+			from:     time.Date(2025, 1, 2, 3, 0, 0, 0, plus5),
+			to:       at(1, 23),
+			wantDays: []time.Time{at(1, 0)},
+			want:     []any{0.9},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ticker := stock.Ticker("ACME")
+			timeline := AlignScores(map[stock.Ticker][]ScorePoint{ticker: tt.points}, tt.from, tt.to)
+
+			if len(timeline.Days) != len(tt.wantDays) {
+				t.Fatalf("days = %v, want %v", timeline.Days, tt.wantDays)
+			}
+			for i, day := range timeline.Days {
+				if !day.Equal(tt.wantDays[i]) {
+					t.Errorf("day %d = %s, want %s", i, day, tt.wantDays[i])
+				}
+			}
+
+			scores := timeline.Scores[ticker]
+			if len(scores) != len(tt.want) {
+				t.Fatalf("scores = %d, want %d", len(scores), len(tt.want))
+			}
+			for i, want := range tt.want {
+				switch {
+				case want == nil && scores[i] != nil:
+					t.Errorf("day %d score = %v, want nil", i, *scores[i])
+				case want != nil && scores[i] == nil:
+					t.Errorf("day %d score = nil, want %v", i, want)
+				case want != nil && *scores[i] != want.(float64):
+					t.Errorf("day %d score = %v, want %v", i, *scores[i], want)
+				}
+			}
+		})
+	}
+}
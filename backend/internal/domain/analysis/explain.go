@@ -0,0 +1,49 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"stockapi/internal/domain/stock"
+)
+
+// StockExplanation justifies the score and recommendation of one stock
+type StockExplanation struct {
+	Stock          *stock.Stock
+	Score          stock.ScoreExplanation
+	Recommendation string
+	// Indicators are nil when the stock lacks the data to compute them
+	Indicators map[string]float64
+	// Excluded is why the stock is left out of the recommendations, or nil
+	Excluded *stock.DomainError
+}
+
+// ExplainStock breaks down the score of the stored stock for the ticker. The
+// explanation is returned even for stocks the recommendations leave out.
+func (s *AnalysisService) ExplainStock(ctx context.Context, ticker stock.Ticker, opts Options) (*StockExplanation, error) {
+	stk, err := s.stockRepo.FindByTicker(ctx, ticker)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching stock for explanation: %w", err)
+	}
+	return s.explain(stk, opts), nil
+}
+
+// explain scores the stock exactly as the recommendations do
+func (s *AnalysisService) explain(stk *stock.Stock, opts Options) *StockExplanation {
+	score := stk.ExplainInvestmentScore(opts.HalfLife)
+	explanation := &StockExplanation{
+		Stock:          stk,
+		Score:          score,
+		Recommendation: determineRecommendation(score.Score),
+	}
+
+	if s.hasRequiredData(stk) {
+		explanation.Indicators = calculateIndicators(stk)
+	}
+	if opts.IsStale(stk) && !opts.IncludeStale {
+		explanation.Excluded = stock.ErrStaleData
+	} else if err := s.validateForAnalysis(stk); err != nil {
+		errors.As(err, &explanation.Excluded)
+	}
+	return explanation
+}
@@ -0,0 +1,86 @@
+package analysis
+
+import (
+	"context"
+	"stockapi/internal/domain/stock"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ScorePoint is one ticker's result in one analysis run
+type ScorePoint struct {
+	Ticker         stock.Ticker
+	StockID        uuid.UUID
+	Score          float64
+	Indicators     map[string]float64
+	Recommendation string
+	AnalyzedAt     time.Time
+	// DataVersion is the stock data version the run scored
+	DataVersion int64
+}
+
+// RecommendationFlip is a change of recommendation between consecutive runs
+type RecommendationFlip struct {
+	At   time.Time
+	From string
+	To   string
+	// Direction is +1 when the recommendation improved and -1 when it worsened
+	Direction int
+}
+
+type ScoreHistoryRepository interface {
+	// SaveScores keeps one point per ticker and UTC day, the latest analyzed,
+	// so repeated runs and replicas replace the day's point instead of adding
+	// to it
+	SaveScores(ctx context.Context, points []ScorePoint) error
+	// FindScores returns the ticker's points in [from, to], oldest first
+	FindScores(ctx context.Context, ticker stock.Ticker, from, to time.Time) ([]ScorePoint, error)
+	// PruneScores deletes the points analyzed before the given time and
+	// returns how many were removed
+	PruneScores(ctx context.Context, before time.Time) (int64, error)
+}
+
+// recommendationLevels orders the recommendations made by determineRecommendation
+var recommendationLevels = map[string]int{
+	"Strong Sell": 1,
+	"Sell":        2,
+	"Hold":        3,
+	"Buy":         4,
+	"Strong Buy":  5,
+}
+
+// ScorePointOf records the result of an analysis
+func ScorePointOf(a StockAnalysis) ScorePoint {
+	return ScorePoint{
+		Ticker:         a.Stock.Ticker,
+		StockID:        a.Stock.ID,
+		Score:          a.Score,
+		Indicators:     a.Indicators,
+		Recommendation: a.Recommendation,
+		AnalyzedAt:     a.LastUpdated,
+	}
+}
+
+// DetectFlips lists the recommendation changes in points ordered oldest first
+func DetectFlips(points []ScorePoint) []RecommendationFlip {
+	var flips []RecommendationFlip
+	for i := 1; i < len(points); i++ {
+		prev, curr := points[i-1], points[i]
+		if prev.Recommendation == curr.Recommendation {
+			continue
+		}
+
+		direction := -1
+		if recommendationLevels[curr.Recommendation] > recommendationLevels[prev.Recommendation] {
+			direction = 1
+		}
+		flips = append(flips, RecommendationFlip{
+			At:        curr.AnalyzedAt,
+			From:      prev.Recommendation,
+			To:        curr.Recommendation,
+			Direction: direction,
+		})
+	}
+	return flips
+}
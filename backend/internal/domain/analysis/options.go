@@ -0,0 +1,39 @@
+package analysis
+
+import (
+	"fmt"
+	"stockapi/internal/domain/stock"
+	"time"
+)
+
+// DefaultStalenessWindow is how long after its rating action a stock is
+// analyzed unless configured otherwise
+const DefaultStalenessWindow = 24 * time.Hour
+
+// Options tune a single analysis
+type Options struct {
+	// StalenessWindow is how long after its rating action a stock is analyzed
+	StalenessWindow time.Duration
+	// HalfLife is how long a rating signal takes to lose half its weight;
+	// zero disables the decay
+	HalfLife time.Duration
+	// IncludeStale keeps stocks outside the window, flagged as stale
+	IncludeStale bool
+}
+
+func DefaultOptions() Options {
+	return Options{
+		StalenessWindow: DefaultStalenessWindow,
+		HalfLife:        stock.DefaultRatingHalfLife,
+	}
+}
+
+// Key identifies the options in caches of analysis results
+func (o Options) Key() string {
+	return fmt.Sprintf("window=%s,half_life=%s,stale=%t", o.StalenessWindow, o.HalfLife, o.IncludeStale)
+}
+
+// IsStale reports whether the stock's rating action is outside the window
+func (o Options) IsStale(stk *stock.Stock) bool {
+	return time.Since(stk.Time) > o.StalenessWindow
+}
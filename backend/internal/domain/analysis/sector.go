@@ -0,0 +1,133 @@
+package analysis
+
+import (
+	"sort"
+	"stockapi/internal/domain/stock"
+	"time"
+)
+
+// SectorSummary aggregates analyst sentiment over the stocks of a sector, or
+// of one industry within it
+type SectorSummary struct {
+	Sector     string
+	Industry   string
+	Stocks     int
+	Upgrades   int
+	Downgrades int
+	// AverageTargetGrowth is the mean price target change in percent
+	AverageTargetGrowth float64
+	// AverageScore is the mean score of the Analyzed stocks; stocks excluded
+	// from the recommendations are only counted in the other figures
+	AverageScore float64
+	Analyzed     int
+}
+
+// NetSentiment is the number of upgrades minus downgrades
+func (s SectorSummary) NetSentiment() int {
+	return s.Upgrades - s.Downgrades
+}
+
+// SummarizeSectors aggregates the stocks of the result whose rating action is
+// not older than since, by sector. Sectors are ordered by net sentiment, so
+// the ones analysts rotate into come first.
+func SummarizeSectors(result *Result, since time.Time) []SectorSummary {
+	return summarize(result, since, func(stk *stock.Stock) (sectorKey, bool) {
+		return sectorKey{sector: stk.SectorName()}, true
+	})
+}
+
+// SummarizeIndustries aggregates the stocks of one sector by industry
+func SummarizeIndustries(result *Result, sector string, since time.Time) []SectorSummary {
+	return summarize(result, since, func(stk *stock.Stock) (sectorKey, bool) {
+		if stk.SectorName() != sector {
+			return sectorKey{}, false
+		}
+		key := sectorKey{sector: sector}
+		if stk.Classification != nil {
+			key.industry = stk.Classification.Industry
+		}
+		return key, true
+	})
+}
+
+type sectorKey struct {
+	sector   string
+	industry string
+}
+
+type sectorTotals struct {
+	summary     SectorSummary
+	growthSum   float64
+	growthCount int
+	scoreSum    float64
+}
+
+func (t *sectorTotals) add(stk *stock.Stock) {
+	t.summary.Stocks++
+	switch direction := stk.Rating.Direction(); {
+	case direction > 0:
+		t.summary.Upgrades++
+	case direction < 0:
+		t.summary.Downgrades++
+	}
+	if stk.Target.From.Amount > 0 {
+		t.growthSum += calculatePriceTargetGrowth(stk)
+		t.growthCount++
+	}
+}
+
+// summarize groups the stocks under the key returned by group, which reports
+// false for stocks to leave out
+func summarize(result *Result, since time.Time, group func(*stock.Stock) (sectorKey, bool)) []SectorSummary {
+	totals := make(map[sectorKey]*sectorTotals)
+	totalsOf := func(stk *stock.Stock) *sectorTotals {
+		if stk.Time.Before(since) {
+			return nil
+		}
+		key, ok := group(stk)
+		if !ok {
+			return nil
+		}
+		t, ok := totals[key]
+		if !ok {
+			t = &sectorTotals{summary: SectorSummary{Sector: key.sector, Industry: key.industry}}
+			totals[key] = t
+		}
+		return t
+	}
+
+	for _, a := range result.Analyses {
+		if t := totalsOf(a.Stock); t != nil {
+			t.add(a.Stock)
+			t.scoreSum += a.Score
+			t.summary.Analyzed++
+		}
+	}
+	for _, e := range result.Excluded {
+		if t := totalsOf(e.Stock); t != nil {
+			t.add(e.Stock)
+		}
+	}
+
+	summaries := make([]SectorSummary, 0, len(totals))
+	for _, t := range totals {
+		if t.growthCount > 0 {
+			t.summary.AverageTargetGrowth = t.growthSum / float64(t.growthCount)
+		}
+		if t.summary.Analyzed > 0 {
+			t.summary.AverageScore = t.scoreSum / float64(t.summary.Analyzed)
+		}
+		summaries = append(summaries, t.summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		a, b := summaries[i], summaries[j]
+		if a.NetSentiment() != b.NetSentiment() {
+			return a.NetSentiment() > b.NetSentiment()
+		}
+		if a.Sector != b.Sector {
+			return a.Sector < b.Sector
+		}
+		return a.Industry < b.Industry
+	})
+	return summaries
+}
@@ -2,6 +2,7 @@ package analysis
 
 import (
 	"context"
+	"errors"
 	"sort"
 	"stockapi/internal/domain/shared"
 	"stockapi/internal/domain/stock"
@@ -11,12 +12,14 @@ import (
 type AnalysisService struct {
 	stockRepo stock.Repository
 	logger    *shared.DomainLogger
+	defaults  Options
 }
 
-func NewAnalysisService(repo stock.Repository, logger *shared.DomainLogger) *AnalysisService {
+func NewAnalysisService(repo stock.Repository, logger *shared.DomainLogger, defaults Options) *AnalysisService {
 	return &AnalysisService{
 		stockRepo: repo,
 		logger:    logger,
+		defaults:  defaults,
 	}
 }
 
@@ -26,6 +29,35 @@ type StockAnalysis struct {
 	Indicators     map[string]float64
 	Recommendation string
 	LastUpdated    time.Time
+	// Stale is set on stocks outside the staleness window, which are only
+	// analyzed when Options.IncludeStale is set
+	Stale bool
+}
+
+// Exclusion is a stock left out of the recommendations and the reason why
+type Exclusion struct {
+	Stock  *stock.Stock
+	Reason *stock.DomainError
+}
+
+// Result holds the analyzed stocks, best first, and the excluded ones in
+// repository order
+type Result struct {
+	Analyses []StockAnalysis
+	Excluded []Exclusion
+}
+
+const (
+	IndicatorPriceTargetGrowth = "price_target_growth"
+	IndicatorRatingImpact      = "rating_impact"
+	IndicatorBrokerConfidence  = "broker_confidence"
+)
+
+// IndicatorNames lists the indicators computed for every analysis, in display order
+var IndicatorNames = []string{
+	IndicatorPriceTargetGrowth,
+	IndicatorRatingImpact,
+	IndicatorBrokerConfidence,
 }
 
 // BrokerTier represents the prestige level of a broker
@@ -119,7 +151,12 @@ var brokerTiers = map[string]BrokerTier{
 	"Northcoast Research":      TierC,
 }
 
-func (s *AnalysisService) AnalyzeStocks(ctx context.Context) ([]StockAnalysis, error) {
+// DefaultOptions returns the configured analysis options
+func (s *AnalysisService) DefaultOptions() Options {
+	return s.defaults
+}
+
+func (s *AnalysisService) AnalyzeStocks(ctx context.Context, opts Options) (*Result, error) {
 	start := time.Now()
 	stocks, err := s.stockRepo.FindAll(ctx)
 	if err != nil {
@@ -133,74 +170,82 @@ func (s *AnalysisService) AnalyzeStocks(ctx context.Context) ([]StockAnalysis, e
 		return nil, stock.ErrAnalysisNotPossible
 	}
 
-	var analyses []StockAnalysis
+	result := &Result{}
 	for _, stk := range stocks {
-		// Check if data is not stale
-		if time.Since(stk.Time) > 24*time.Hour {
-			s.logger.Warn(ctx, "Stale data detected", map[string]interface{}{
-				"stock_id":    stk.ID,
-				"last_update": stk.Time,
-			})
-			continue
-		}
-
-		analysis, err := s.analyzeStock(ctx, stk)
-		if err != nil {
-			s.logger.LogError(ctx, err, map[string]interface{}{
-				"operation": "analyzing stock",
-				"stock_id":  stk.ID,
-			})
+		analysis, reason := s.analyzeIfEligible(ctx, stk, opts)
+		if reason != nil {
+			result.Excluded = append(result.Excluded, Exclusion{Stock: stk, Reason: reason})
 			continue
 		}
-		analyses = append(analyses, analysis)
-	}
-
-	if len(analyses) == 0 {
-		return nil, stock.ErrStaleData
+		result.Analyses = append(result.Analyses, analysis)
 	}
 
 	// Sort by score
-	sort.Slice(analyses, func(i, j int) bool {
-		return analyses[i].Score > analyses[j].Score
+	sort.Slice(result.Analyses, func(i, j int) bool {
+		return result.Analyses[i].Score > result.Analyses[j].Score
 	})
 
 	duration := time.Since(start)
 	s.logger.Info(ctx, "Stock analysis completed", map[string]interface{}{
 		"stocks_analyzed": len(stocks),
+		"stocks_excluded": len(result.Excluded),
 		"duration_ms":     duration.Milliseconds(),
 	})
-	return analyses, nil
+	return result, nil
 }
 
-func (s *AnalysisService) analyzeStock(ctx context.Context, stk *stock.Stock) (StockAnalysis, error) {
-	// Validate that we have enough data for analysis
-	start := time.Now()
-	if !s.hasRequiredData(stk) {
-		return StockAnalysis{}, stock.ErrAnalysisNotPossible
-	}
+// StreamAnalyses analyzes stocks one at a time straight from the repository
+// cursor and hands each result to fn. Results follow repository order (newest
+// first) rather than score order, so nothing has to be buffered.
+func (s *AnalysisService) StreamAnalyses(ctx context.Context, opts Options, fn func(StockAnalysis) error) error {
+	return s.stockRepo.Iterate(ctx, func(stk *stock.Stock) error {
+		analysis, reason := s.analyzeIfEligible(ctx, stk, opts)
+		if reason != nil {
+			return nil
+		}
+		return fn(analysis)
+	})
+}
 
-	// Validate rating transition
-	if !isValidRatingTransition(stk.Rating.From, stk.Rating.To) {
-		return StockAnalysis{}, stock.ErrInvalidRatingTransition
+// analyzeIfEligible returns why the stock is excluded when it cannot be
+// analyzed, or is stale and the options leave stale stocks out
+func (s *AnalysisService) analyzeIfEligible(ctx context.Context, stk *stock.Stock, opts Options) (StockAnalysis, *stock.DomainError) {
+	// Check if data is not stale
+	stale := opts.IsStale(stk)
+	if stale && !opts.IncludeStale {
+		s.logger.Warn(ctx, "Stale data detected", map[string]interface{}{
+			"stock_id":    stk.ID,
+			"last_update": stk.Time,
+		})
+		return StockAnalysis{}, stock.ErrStaleData
 	}
 
-	// Validate price target
-	if stk.Target.From.Amount == stk.Target.To.Amount {
-		return StockAnalysis{}, stock.ErrInvalidPriceTarget
+	analysis, err := s.analyzeStock(ctx, stk, opts)
+	if err != nil {
+		s.logger.LogError(ctx, err, map[string]interface{}{
+			"operation": "analyzing stock",
+			"stock_id":  stk.ID,
+		})
+		reason := stock.ErrAnalysisNotPossible
+		errors.As(err, &reason)
+		return StockAnalysis{}, reason
 	}
+	analysis.Stale = stale
+	return analysis, nil
+}
 
-	score := stk.CalculateInvestmentScore()
-
-	indicators := map[string]float64{
-		"price_target_growth": calculatePriceTargetGrowth(stk),
-		"rating_impact":       calculateRatingImpact(stk),
-		"broker_confidence":   calculateBrokerConfidence(stk),
+func (s *AnalysisService) analyzeStock(ctx context.Context, stk *stock.Stock, opts Options) (StockAnalysis, error) {
+	start := time.Now()
+	if err := s.validateForAnalysis(stk); err != nil {
+		return StockAnalysis{}, err
 	}
 
+	score := stk.ExplainInvestmentScore(opts.HalfLife).Score
+
 	analysis := StockAnalysis{
 		Stock:          stk,
 		Score:          score,
-		Indicators:     indicators,
+		Indicators:     calculateIndicators(stk),
 		Recommendation: determineRecommendation(score),
 		LastUpdated:    time.Now(),
 	}
@@ -209,6 +254,33 @@ func (s *AnalysisService) analyzeStock(ctx context.Context, stk *stock.Stock) (S
 	return analysis, nil
 }
 
+// validateForAnalysis checks the stock has the data the analysis relies on
+func (s *AnalysisService) validateForAnalysis(stk *stock.Stock) error {
+	// Validate that we have enough data for analysis
+	if !s.hasRequiredData(stk) {
+		return stock.ErrAnalysisNotPossible
+	}
+
+	// Validate rating transition
+	if !isValidRatingTransition(stk.Rating.From, stk.Rating.To) {
+		return stock.ErrInvalidRatingTransition
+	}
+
+	// Validate price target
+	if stk.Target.From.Amount == stk.Target.To.Amount {
+		return stock.ErrInvalidPriceTarget
+	}
+	return nil
+}
+
+func calculateIndicators(stk *stock.Stock) map[string]float64 {
+	return map[string]float64{
+		IndicatorPriceTargetGrowth: calculatePriceTargetGrowth(stk),
+		IndicatorRatingImpact:      calculateRatingImpact(stk),
+		IndicatorBrokerConfidence:  calculateBrokerConfidence(stk),
+	}
+}
+
 func (s *AnalysisService) hasRequiredData(stk *stock.Stock) bool {
 	return stk != nil &&
 		stk.Target.From.Amount > 0 &&
@@ -218,37 +290,8 @@ func (s *AnalysisService) hasRequiredData(stk *stock.Stock) bool {
 }
 
 func isValidRatingTransition(from, to stock.Rating) bool {
-	// Map of rating levels to determine the magnitude of the change
-	ratingLevels := map[stock.Rating]int{
-		// Level 4: Very Positive
-		stock.StrongBuy:  4,
-		stock.Outperform: 4,
-		stock.Overweight: 4,
-
-		// Level 3: Positive
-		stock.Buy:      3,
-		stock.Positive: 3,
-
-		// Level 2: Neutral
-		stock.Hold:          2,
-		stock.Neutral:       2,
-		stock.EqualWeight:   2,
-		stock.MarketPerform: 2,
-
-		// Level 1: Negative
-		stock.Underweight:  1,
-		stock.Underperform: 1,
-		stock.Sell:         1,
-	}
-
-	fromLevel := ratingLevels[from]
-	toLevel := ratingLevels[to]
-
-	// Calculates the absolute difference between levels
-	levelDifference := abs(fromLevel - toLevel)
-
 	// Does not allow changes of more than one level in a single update
-	return levelDifference <= 1
+	return abs(from.Level()-to.Level()) <= 1
 }
 
 // Auxiliary function to calculate the absolute value
@@ -297,11 +340,30 @@ func calculateRatingImpact(s *stock.Stock) float64 {
 	return toScore - fromScore
 }
 
-func getPrestigeScore(brokerage string) float64 {
+// TierOf returns the prestige level of a broker; unknown brokers are Tier C
+func TierOf(brokerage string) BrokerTier {
 	tier, exists := brokerTiers[brokerage]
 	if !exists {
-		return 0.4 // Tier C por defecto
+		return TierC
+	}
+	return tier
+}
+
+func (t BrokerTier) String() string {
+	switch t {
+	case TierS:
+		return "S"
+	case TierA:
+		return "A"
+	case TierB:
+		return "B"
+	default:
+		return "C"
 	}
+}
+
+func getPrestigeScore(brokerage string) float64 {
+	tier := TierOf(brokerage)
 
 	scores := map[BrokerTier]float64{
 		TierS: 1.0,
@@ -377,7 +439,7 @@ func (s *AnalysisService) AnalyzeAllStocks(ctx context.Context) ([]*stock.Stock,
 
 	for i := 0; i < len(sortedStocks)-1; i++ {
 		for j := i + 1; j < len(sortedStocks); j++ {
-			if sortedStocks[i].CalculateInvestmentScore() < sortedStocks[j].CalculateInvestmentScore() {
+			if sortedStocks[i].CalculateInvestmentScore(s.defaults.HalfLife) < sortedStocks[j].CalculateInvestmentScore(s.defaults.HalfLife) {
 				sortedStocks[i], sortedStocks[j] = sortedStocks[j], sortedStocks[i]
 			}
 		}
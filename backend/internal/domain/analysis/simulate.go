@@ -0,0 +1,59 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"stockapi/internal/domain/stock"
+	"time"
+)
+
+// Scenario is a hypothetical rating event on the ticker. Edit fields left nil
+// keep the values of the stored stock for the ticker, so a scenario can
+// describe a brand new event or a modification to the latest one; Time
+// defaults to now for new events.
+type Scenario struct {
+	Ticker string
+	stock.Edit
+}
+
+// Simulation is the explanation of a scenario's score
+type Simulation struct {
+	StockExplanation
+	// Base is the stored stock the scenario modifies, or nil for a new event
+	Base *stock.Stock
+}
+
+// Simulate scores the scenario with the same scorer as the recommendations.
+// Nothing is persisted.
+func (s *AnalysisService) Simulate(ctx context.Context, scenario Scenario, opts Options) (*Simulation, error) {
+	ticker, err := stock.ParseTicker(scenario.Ticker)
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := s.stockRepo.FindByTicker(ctx, ticker)
+	if errors.Is(err, stock.ErrStockNotFound) {
+		base = nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error fetching stock for simulation: %w", err)
+	}
+
+	stk := scenario.apply(ticker, base)
+	return &Simulation{
+		StockExplanation: *s.explain(stk, opts),
+		Base:             base,
+	}, nil
+}
+
+// apply returns a copy of base, or a new stock for the ticker, with the
+// scenario's fields set
+func (sc Scenario) apply(ticker stock.Ticker, base *stock.Stock) *stock.Stock {
+	stk := &stock.Stock{Ticker: ticker, Time: time.Now()}
+	if base != nil {
+		copied := *base
+		stk = &copied
+	}
+	sc.Edit.Apply(stk)
+	return stk
+}
@@ -0,0 +1,43 @@
+package analysis
+
+import (
+	"context"
+	"time"
+)
+
+// Snapshot is a set of analyses computed from one version of the stock data.
+// It is only valid until ExpiresAt, when the oldest analyzed stock goes stale.
+type Snapshot struct {
+	Key         string
+	DataVersion int64
+	ComputedAt  time.Time
+	ExpiresAt   time.Time
+	Result
+}
+
+// Current reports whether the snapshot still describes the given data version
+func (s *Snapshot) Current(version int64, now time.Time) bool {
+	return s.DataVersion == version && now.Before(s.ExpiresAt)
+}
+
+type SnapshotRepository interface {
+	FindSnapshot(ctx context.Context, key string) (*Snapshot, error)
+	SaveSnapshot(ctx context.Context, snapshot *Snapshot) error
+}
+
+// StaleAt returns when the first of the fresh analyzed stocks falls out of the
+// staleness window, which changes the result without any data write. It is
+// the zero time when no analysis can go stale.
+func StaleAt(analyses []StockAnalysis, window time.Duration) time.Time {
+	var staleAt time.Time
+	for _, a := range analyses {
+		if a.Stale {
+			continue
+		}
+		at := a.Stock.Time.Add(window)
+		if staleAt.IsZero() || at.Before(staleAt) {
+			staleAt = at
+		}
+	}
+	return staleAt
+}
@@ -0,0 +1,162 @@
+package portfolio
+
+import (
+	"stockapi/internal/domain/analysis"
+	"stockapi/internal/domain/stock"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// weightTolerance absorbs rounding when comparing weights with their caps
+const weightTolerance = 1e-9
+
+// Construct builds a portfolio from recommendations ranked best first. Names
+// below the minimum score are skipped and the best MaxNames are kept. Weights
+// are then capped per name and per sector, handing what a cap removes to the
+// names that still have room; whatever no name can take is left in cash.
+func Construct(name string, analyses []analysis.StockAnalysis, constraints Constraints, dataVersion int64) (*Portfolio, error) {
+	if err := constraints.Validate(); err != nil {
+		return nil, err
+	}
+
+	var selected []analysis.StockAnalysis
+	for _, a := range analyses {
+		if a.Score < constraints.MinScore {
+			continue
+		}
+		selected = append(selected, a)
+		if constraints.MaxNames > 0 && len(selected) == constraints.MaxNames {
+			break
+		}
+	}
+	if len(selected) == 0 {
+		return nil, stock.ErrEmptyPortfolio
+	}
+
+	holdings := make([]Holding, len(selected))
+	for i, a := range selected {
+		holdings[i] = Holding{
+			Ticker:         a.Stock.Ticker,
+			StockID:        a.Stock.ID,
+			Sector:         a.Stock.SectorName(),
+			Score:          a.Score,
+			Recommendation: a.Recommendation,
+		}
+	}
+	cash := allocate(holdings, rawWeights(holdings, constraints.Weighting), constraints)
+
+	return &Portfolio{
+		ID:          uuid.New(),
+		Name:        name,
+		Kind:        KindModel,
+		Constraints: constraints,
+		Holdings:    holdings,
+		Cash:        cash,
+		DataVersion: dataVersion,
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+// rawWeights are the uncapped relative weights of the holdings
+func rawWeights(holdings []Holding, weighting Weighting) []float64 {
+	raw := make([]float64, len(holdings))
+	var total float64
+	for i, h := range holdings {
+		raw[i] = 1
+		if weighting == WeightByScore {
+			raw[i] = h.Score
+		}
+		total += raw[i]
+	}
+	// Scores of zero leave nothing to weight by
+	if total == 0 {
+		for i := range raw {
+			raw[i] = 1
+		}
+	}
+	return raw
+}
+
+// allocate sets the holding weights and returns the cash left. Every pass
+// spreads the unfixed budget over the free names in proportion to raw, then
+// fixes the names over the weight cap, or failing that the sectors over
+// theirs. Each pass fixes at least one name, so it ends after len passes.
+func allocate(holdings []Holding, raw []float64, constraints Constraints) float64 {
+	fixed := make([]bool, len(holdings))
+	for {
+		var fixedWeight, freeRaw float64
+		for i := range holdings {
+			if fixed[i] {
+				fixedWeight += holdings[i].Weight
+			} else {
+				freeRaw += raw[i]
+			}
+		}
+		if freeRaw == 0 {
+			return cashLeft(holdings)
+		}
+		for i := range holdings {
+			if !fixed[i] {
+				holdings[i].Weight = (1 - fixedWeight) * raw[i] / freeRaw
+			}
+		}
+
+		if capNames(holdings, fixed, constraints.MaxWeight) {
+			continue
+		}
+		if capSectors(holdings, fixed, constraints.MaxSectorWeight) {
+			continue
+		}
+		return cashLeft(holdings)
+	}
+}
+
+func cashLeft(holdings []Holding) float64 {
+	cash := 1.0
+	for _, h := range holdings {
+		cash -= h.Weight
+	}
+	if cash < weightTolerance {
+		return 0
+	}
+	return cash
+}
+
+// capNames clips and fixes the free names over the weight cap
+func capNames(holdings []Holding, fixed []bool, maxWeight float64) bool {
+	if maxWeight == 0 {
+		return false
+	}
+	capped := false
+	for i := range holdings {
+		if !fixed[i] && holdings[i].Weight > maxWeight+weightTolerance {
+			holdings[i].Weight = maxWeight
+			fixed[i] = true
+			capped = true
+		}
+	}
+	return capped
+}
+
+// capSectors scales down and fixes every name of the sectors over the cap
+func capSectors(holdings []Holding, fixed []bool, maxSectorWeight float64) bool {
+	if maxSectorWeight == 0 {
+		return false
+	}
+	sectorWeights := make(map[string]float64)
+	for _, h := range holdings {
+		sectorWeights[h.Sector] += h.Weight
+	}
+
+	capped := false
+	for i := range holdings {
+		weight := sectorWeights[holdings[i].Sector]
+		if weight > maxSectorWeight+weightTolerance {
+			holdings[i].Weight *= maxSectorWeight / weight
+			fixed[i] = true
+			capped = true
+		}
+	}
+	return capped
+}
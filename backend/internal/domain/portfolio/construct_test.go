@@ -0,0 +1,202 @@
+package portfolio
+
+import (
+	"errors"
+	"math"
+	"stockapi/internal/domain/analysis"
+	"stockapi/internal/domain/stock"
+	"testing"
+)
+
+func holdingsIn(sectors ...string) []Holding {
+	holdings := make([]Holding, len(sectors))
+	for i, sector := range sectors {
+		holdings[i] = Holding{Ticker: stock.Ticker(string(rune('A' + i))), Sector: sector}
+	}
+	return holdings
+}
+
+func assertWeights(t *testing.T, holdings []Holding, want []float64) {
+	t.Helper()
+	if len(holdings) != len(want) {
+		t.Fatalf("holdings = %d, want %d", len(holdings), len(want))
+	}
+	for i, h := range holdings {
+		if math.Abs(h.Weight-want[i]) > 1e-9 {
+			t.Errorf("%s weight = %v, want %v", h.Ticker, h.Weight, want[i])
+		}
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name        string
+		holdings    []Holding
+		raw         []float64
+		constraints Constraints
+		wantWeights []float64
+		wantCash    float64
+	}{
+		{
+			name:        "no caps",
+			holdings:    holdingsIn("Tech", "Tech", "Health", "Energy"),
+			raw:         []float64{1, 1, 1, 1},
+			wantWeights: []float64{0.25, 0.25, 0.25, 0.25},
+		},
+		{
+			name:        "proportional to raw",
+			holdings:    holdingsIn("Tech", "Health"),
+			raw:         []float64{3, 1},
+			wantWeights: []float64{0.75, 0.25},
+		},
+		{
+			name:        "name cap hands the excess to the others",
+			holdings:    holdingsIn("Tech", "Health", "Energy", "Utilities"),
+			raw:         []float64{4, 3, 2, 1},
+			constraints: Constraints{MaxWeight: 0.3},
+			wantWeights: []float64{0.3, 0.3, 0.4 * 2 / 3, 0.4 / 3},
+		},
+		{
+			name:        "name cap leaves cash when no name has room",
+			holdings:    holdingsIn("Tech", "Health", "Energy"),
+			raw:         []float64{1, 1, 1},
+			constraints: Constraints{MaxWeight: 0.2},
+			wantWeights: []float64{0.2, 0.2, 0.2},
+			wantCash:    0.4,
+		},
+		{
+			name:        "sector cap scales its names down",
+			holdings:    holdingsIn("Tech", "Tech", "Health", "Energy"),
+			raw:         []float64{1, 1, 1, 1},
+			constraints: Constraints{MaxSectorWeight: 0.4},
+			wantWeights: []float64{0.2, 0.2, 0.3, 0.3},
+		},
+		{
+			name:        "sector cap then name cap",
+			holdings:    holdingsIn("Tech", "Tech", "Tech", "Health"),
+			raw:         []float64{1, 1, 1, 1},
+			constraints: Constraints{MaxWeight: 0.25, MaxSectorWeight: 0.4},
+			wantWeights: []float64{0.4 / 3, 0.4 / 3, 0.4 / 3, 0.25},
+			wantCash:    0.35,
+		},
+		{
+			name:        "single sector under its cap",
+			holdings:    holdingsIn("Tech", "Tech"),
+			raw:         []float64{1, 1},
+			constraints: Constraints{MaxSectorWeight: 0.5},
+			wantWeights: []float64{0.25, 0.25},
+			wantCash:    0.5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cash := allocate(tt.holdings, tt.raw, tt.constraints)
+
+			assertWeights(t, tt.holdings, tt.wantWeights)
+			if math.Abs(cash-tt.wantCash) > 1e-9 {
+				t.Errorf("cash = %v, want %v", cash, tt.wantCash)
+			}
+		})
+	}
+}
+
+func TestConstruct(t *testing.T) {
+	analyses := []analysis.StockAnalysis{
+		{Stock: &stock.Stock{Ticker: "AAA"}, Score: 0.9, Recommendation: "Strong Buy"},
+		{Stock: &stock.Stock{Ticker: "BBB"}, Score: 0.6, Recommendation: "Buy"},
+		{Stock: &stock.Stock{Ticker: "CCC"}, Score: 0.3, Recommendation: "Sell"},
+		{Stock: &stock.Stock{Ticker: "DDD"}, Score: 0.1, Recommendation: "Strong Sell"},
+	}
+
+	tests := []struct {
+		name        string
+		analyses    []analysis.StockAnalysis
+		constraints Constraints
+		wantTickers []stock.Ticker
+		wantWeights []float64
+		wantErr     error
+	}{
+		{
+			name:        "equal weights",
+			analyses:    analyses,
+			constraints: Constraints{Weighting: WeightEqually},
+			wantTickers: []stock.Ticker{"AAA", "BBB", "CCC", "DDD"},
+			wantWeights: []float64{0.25, 0.25, 0.25, 0.25},
+		},
+		{
+			name:        "score weights of the best names",
+			analyses:    analyses,
+			constraints: Constraints{MaxNames: 2, Weighting: WeightByScore},
+			wantTickers: []stock.Ticker{"AAA", "BBB"},
+			wantWeights: []float64{0.6, 0.4},
+		},
+		{
+			name:        "minimum score",
+			analyses:    analyses,
+			constraints: Constraints{MinScore: 0.5, Weighting: WeightEqually},
+			wantTickers: []stock.Ticker{"AAA", "BBB"},
+			wantWeights: []float64{0.5, 0.5},
+		},
+		{
+			name: "zero scores fall back to equal weights",
+			analyses: []analysis.StockAnalysis{
+				{Stock: &stock.Stock{Ticker: "AAA"}},
+				{Stock: &stock.Stock{Ticker: "BBB"}},
+			},
+			constraints: Constraints{Weighting: WeightByScore},
+			wantTickers: []stock.Ticker{"AAA", "BBB"},
+			wantWeights: []float64{0.5, 0.5},
+		},
+		{
+			name:        "nothing above the minimum score",
+			analyses:    analyses,
+			constraints: Constraints{MinScore: 0.95, Weighting: WeightEqually},
+			wantErr:     stock.ErrEmptyPortfolio,
+		},
+		{
+			name:        "invalid constraints",
+			analyses:    analyses,
+			constraints: Constraints{MaxWeight: 1.5, Weighting: WeightEqually},
+			wantErr:     stock.ErrInvalidConstraints,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Construct("test", tt.analyses, tt.constraints, 7)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if p.Kind != KindModel || p.DataVersion != 7 {
+				t.Errorf("kind = %q, data version = %d, want %q and 7", p.Kind, p.DataVersion, KindModel)
+			}
+			tickers := make([]stock.Ticker, len(p.Holdings))
+			for i, h := range p.Holdings {
+				tickers[i] = h.Ticker
+				if h.Sector != stock.UnclassifiedSector {
+					t.Errorf("%s sector = %q, want %q", h.Ticker, h.Sector, stock.UnclassifiedSector)
+				}
+			}
+			if len(tickers) != len(tt.wantTickers) {
+				t.Fatalf("tickers = %v, want %v", tickers, tt.wantTickers)
+			}
+			for i := range tickers {
+				if tickers[i] != tt.wantTickers[i] {
+					t.Fatalf("tickers = %v, want %v", tickers, tt.wantTickers)
+				}
+			}
+			assertWeights(t, p.Holdings, tt.wantWeights)
+			if p.Cash != 0 {
+				t.Errorf("cash = %v, want 0", p.Cash)
+			}
+		})
+	}
+}
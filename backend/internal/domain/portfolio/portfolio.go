@@ -0,0 +1,118 @@
+package portfolio
+
+import (
+	"context"
+	"fmt"
+	"stockapi/internal/domain/stock"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kind tells constructed portfolios from uploaded holdings
+type Kind string
+
+const (
+	KindModel    Kind = "model"
+	KindHoldings Kind = "holdings"
+)
+
+// Weighting decides how the selected names share the portfolio
+type Weighting string
+
+const (
+	WeightByScore Weighting = "score"
+	WeightEqually Weighting = "equal"
+)
+
+// Constraints bound a constructed portfolio. Zero limits are not enforced.
+type Constraints struct {
+	// MaxNames caps the number of holdings, taken best score first
+	MaxNames int
+	// MaxWeight caps the weight of a single holding
+	MaxWeight float64
+	// MaxSectorWeight caps the combined weight of the holdings of a sector
+	MaxSectorWeight float64
+	// MinScore leaves out recommendations scoring below it
+	MinScore  float64
+	Weighting Weighting
+}
+
+// Validate reports the first constraint outside its range
+func (c Constraints) Validate() error {
+	switch {
+	case c.MaxNames < 0:
+		return fmt.Errorf("%w: max_names cannot be negative", stock.ErrInvalidConstraints)
+	case c.MaxWeight < 0 || c.MaxWeight > 1:
+		return fmt.Errorf("%w: max_weight must be between 0 and 1", stock.ErrInvalidConstraints)
+	case c.MaxSectorWeight < 0 || c.MaxSectorWeight > 1:
+		return fmt.Errorf("%w: max_sector_weight must be between 0 and 1", stock.ErrInvalidConstraints)
+	case c.MinScore < 0 || c.MinScore > 1:
+		return fmt.Errorf("%w: min_score must be between 0 and 1", stock.ErrInvalidConstraints)
+	case c.Weighting != WeightByScore && c.Weighting != WeightEqually:
+		return fmt.Errorf("%w: weighting must be score or equal", stock.ErrInvalidConstraints)
+	}
+	return nil
+}
+
+// Holding is the target weight of one name in a model portfolio, or a
+// position of uploaded holdings
+type Holding struct {
+	Ticker         stock.Ticker
+	StockID        uuid.UUID
+	Sector         string
+	Score          float64
+	Recommendation string
+	Weight         float64
+	// Quantity and CostBasis, the price paid per share, are only set on
+	// uploaded holdings; CostBasis is zero when unknown
+	Quantity  float64
+	CostBasis float64
+}
+
+// Portfolio is a basket built from the recommendations of one data version.
+// Weights and Cash add up to 1; Cash is the share the caps left unallocated.
+// Uploaded holdings have no constraints, data version or cash.
+type Portfolio struct {
+	ID          uuid.UUID
+	Name        string
+	Kind        Kind
+	Constraints Constraints
+	Holdings    []Holding
+	Cash        float64
+	DataVersion int64
+	CreatedAt   time.Time
+}
+
+// NewHoldings stores positions as they are held. Holdings are weighted by
+// cost when every position has a cost basis and left unweighted otherwise.
+func NewHoldings(name string, holdings []Holding) *Portfolio {
+	var total float64
+	for _, h := range holdings {
+		if h.CostBasis <= 0 {
+			total = 0
+			break
+		}
+		total += h.Quantity * h.CostBasis
+	}
+	if total > 0 {
+		for i := range holdings {
+			holdings[i].Weight = holdings[i].Quantity * holdings[i].CostBasis / total
+		}
+	}
+
+	return &Portfolio{
+		ID:        uuid.New(),
+		Name:      name,
+		Kind:      KindHoldings,
+		Holdings:  holdings,
+		CreatedAt: time.Now(),
+	}
+}
+
+type Repository interface {
+	SavePortfolio(ctx context.Context, portfolio *Portfolio) error
+	FindPortfolio(ctx context.Context, id uuid.UUID) (*Portfolio, error)
+	// FindPortfolios returns the latest portfolios first, without holdings
+	FindPortfolios(ctx context.Context, limit int) ([]Portfolio, error)
+}
@@ -0,0 +1,139 @@
+package portfolio
+
+import (
+	"stockapi/internal/domain/analysis"
+	"stockapi/internal/domain/stock"
+	"time"
+)
+
+// DefaultReviewWindow is how far back a review looks for downgrades
+const DefaultReviewWindow = 30 * 24 * time.Hour
+
+// BrokerRating is one rating action of a broker on a holding
+type BrokerRating struct {
+	Brokerage string
+	Tier      analysis.BrokerTier
+	Action    string
+	Rating    stock.RatingChange
+	Target    stock.TargetPrice
+	Time      time.Time
+}
+
+// TargetChange is the relative move of the broker's price target, or zero
+// when the previous target is unknown
+func (r BrokerRating) TargetChange() float64 {
+	if r.Target.From.Amount <= 0 {
+		return 0
+	}
+	return r.Target.To.Amount/r.Target.From.Amount - 1
+}
+
+// negative reports whether a top tier broker turned against the holding: a
+// downgrade or a negative rating from a Tier S or Tier A broker
+func (r BrokerRating) negative() bool {
+	if r.Tier != analysis.TierS && r.Tier != analysis.TierA {
+		return false
+	}
+	return r.Rating.Direction() < 0 || r.Rating.To.Level() == 1
+}
+
+// HoldingReview is the rating picture of one holding
+type HoldingReview struct {
+	Holding Holding
+	Company string
+	// Ratings holds the latest action of each broker, newest first
+	Ratings []BrokerRating
+	// Downgrades lists the downgrades within the review window, newest first
+	Downgrades []BrokerRating
+	// ConsensusTarget is the mean of the latest broker targets, zero when no
+	// broker has a target
+	ConsensusTarget float64
+	// TargetUpside is the consensus target over the cost basis; nil when
+	// either is unknown
+	TargetUpside *float64
+	// RiskBrokers are the Tier S and Tier A brokers whose latest action within
+	// the review window turned negative
+	RiskBrokers []string
+}
+
+func (r HoldingReview) RiskFlag() bool {
+	return len(r.RiskBrokers) > 0
+}
+
+// Review is the rating picture of every holding of a portfolio
+type Review struct {
+	Portfolio *Portfolio
+	Since     time.Time
+	Holdings  []HoldingReview
+}
+
+// Flagged counts the holdings with a risk flag
+func (r *Review) Flagged() int {
+	flagged := 0
+	for _, h := range r.Holdings {
+		if h.RiskFlag() {
+			flagged++
+		}
+	}
+	return flagged
+}
+
+// ReviewHoldings reviews the holdings against their rating actions, given
+// newest first. Downgrades and risk flags only consider actions since the
+// given time; the latest rating of each broker is taken from all of them.
+func ReviewHoldings(p *Portfolio, actions []*stock.Stock, since time.Time) *Review {
+	byTicker := make(map[stock.Ticker][]*stock.Stock)
+	for _, a := range actions {
+		byTicker[a.Ticker] = append(byTicker[a.Ticker], a)
+	}
+
+	review := &Review{
+		Portfolio: p,
+		Since:     since,
+		Holdings:  make([]HoldingReview, len(p.Holdings)),
+	}
+	for i, h := range p.Holdings {
+		review.Holdings[i] = reviewHolding(h, byTicker[h.Ticker], since)
+	}
+	return review
+}
+
+func reviewHolding(h Holding, actions []*stock.Stock, since time.Time) HoldingReview {
+	review := HoldingReview{Holding: h}
+	if len(actions) > 0 {
+		review.Company = actions[0].Company
+	}
+
+	for _, a := range actions {
+		if !a.Time.Before(since) && a.Rating.Direction() < 0 {
+			review.Downgrades = append(review.Downgrades, brokerRatingOf(a))
+		}
+	}
+
+	latest := analysis.LatestByBroker(actions)
+	for _, a := range latest {
+		rating := brokerRatingOf(a)
+		review.Ratings = append(review.Ratings, rating)
+		if !a.Time.Before(since) && rating.negative() {
+			review.RiskBrokers = append(review.RiskBrokers, a.Brokerage)
+		}
+	}
+
+	review.ConsensusTarget = analysis.ConsensusOf(latest).AverageTarget
+	if review.ConsensusTarget > 0 && h.CostBasis > 0 {
+		upside := review.ConsensusTarget/h.CostBasis - 1
+		review.TargetUpside = &upside
+	}
+	return review
+}
+
+func brokerRatingOf(a *stock.Stock) BrokerRating {
+	return BrokerRating{
+		Brokerage: a.Brokerage,
+		Tier:      analysis.TierOf(a.Brokerage),
+		Action:    a.Action,
+		Rating:    a.Rating,
+		Target:    a.Target,
+		Time:      a.Time,
+	}
+}
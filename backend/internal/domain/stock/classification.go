@@ -0,0 +1,59 @@
+package stock
+
+import (
+	"context"
+	"time"
+)
+
+// MarketCapBucket groups companies by market capitalization
+type MarketCapBucket string
+
+const (
+	MarketCapMega  MarketCapBucket = "mega"
+	MarketCapLarge MarketCapBucket = "large"
+	MarketCapMid   MarketCapBucket = "mid"
+	MarketCapSmall MarketCapBucket = "small"
+	MarketCapMicro MarketCapBucket = "micro"
+	MarketCapNano  MarketCapBucket = "nano"
+)
+
+var marketCapBuckets = map[MarketCapBucket]bool{
+	MarketCapMega:  true,
+	MarketCapLarge: true,
+	MarketCapMid:   true,
+	MarketCapSmall: true,
+	MarketCapMicro: true,
+	MarketCapNano:  true,
+}
+
+// Valid reports whether the bucket is known; an empty bucket is not
+func (b MarketCapBucket) Valid() bool {
+	return marketCapBuckets[b]
+}
+
+// UnclassifiedSector groups stocks without reference data in sector analytics
+const UnclassifiedSector = "Unclassified"
+
+// Classification is the reference data of the company behind a ticker
+type Classification struct {
+	Ticker    Ticker
+	Sector    string
+	Industry  string
+	MarketCap MarketCapBucket
+	Exchange  string
+	UpdatedAt time.Time
+}
+
+// SectorName returns the sector, or UnclassifiedSector for a stock without
+// reference data
+func (s *Stock) SectorName() string {
+	if s.Classification == nil || s.Classification.Sector == "" {
+		return UnclassifiedSector
+	}
+	return s.Classification.Sector
+}
+
+type ClassificationRepository interface {
+	// SaveClassifications inserts or replaces the classifications by ticker
+	SaveClassifications(ctx context.Context, classifications []Classification) error
+}
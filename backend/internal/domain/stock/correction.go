@@ -0,0 +1,96 @@
+package stock
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ManualSource is the source of stock records written by an admin
+const ManualSource = "manual"
+
+type CorrectionAction string
+
+const (
+	CorrectionCreate  CorrectionAction = "create"
+	CorrectionReplace CorrectionAction = "replace"
+	CorrectionPatch   CorrectionAction = "patch"
+	CorrectionDelete  CorrectionAction = "delete"
+	// CorrectionRelease hands the stock's tickers back to the syncs without
+	// changing the record
+	CorrectionRelease CorrectionAction = "release"
+)
+
+// Correction is a manual change to a stored stock, kept as an audit entry
+type Correction struct {
+	ID      uuid.UUID
+	StockID uuid.UUID
+	Action  CorrectionAction
+	// Admin is the name of the admin who made the change
+	Admin string
+	At    time.Time
+	// Before is nil when the record is created and After is nil when it is
+	// deleted; a release keeps both equal
+	Before *Stock
+	After  *Stock
+}
+
+func NewCorrection(action CorrectionAction, admin string, before, after *Stock) *Correction {
+	c := &Correction{
+		ID:     uuid.New(),
+		Action: action,
+		Admin:  admin,
+		At:     time.Now(),
+		Before: before,
+		After:  after,
+	}
+	if after != nil {
+		c.StockID = after.ID
+	} else if before != nil {
+		c.StockID = before.ID
+	}
+	return c
+}
+
+// LockedTickers are the tickers the correction protects from syncs: the
+// tickers of the record before and after the change, so a renamed or deleted
+// record is not brought back by the next sync. A release locks none.
+func (c *Correction) LockedTickers() []Ticker {
+	if c.Action == CorrectionRelease {
+		return nil
+	}
+	var tickers []Ticker
+	if c.Before != nil {
+		tickers = append(tickers, c.Before.Ticker)
+	}
+	if c.After != nil && (c.Before == nil || c.After.Ticker != c.Before.Ticker) {
+		tickers = append(tickers, c.After.Ticker)
+	}
+	return tickers
+}
+
+// StockLock keeps syncs from overwriting a ticker corrected by hand until an
+// admin releases it
+type StockLock struct {
+	Ticker   Ticker
+	StockID  uuid.UUID
+	LockedBy string
+	LockedAt time.Time
+}
+
+// CorrectionRepository stores manual corrections. Applying one writes the
+// record, its rating action, the audit entry and the ticker locks together.
+type CorrectionRepository interface {
+	// FindStockByID returns ErrStockNotFound when no record has the id
+	FindStockByID(ctx context.Context, id uuid.UUID) (*Stock, error)
+	// ApplyCorrection stores After, or deletes the record when After is nil,
+	// marks the rating action of Before superseded by a manual one for After,
+	// and locks the correction's tickers; a release removes the stock's locks
+	ApplyCorrection(ctx context.Context, c *Correction) error
+	// FindCorrections returns the audit entries of a stock, newest first
+	FindCorrections(ctx context.Context, stockID uuid.UUID, limit int) ([]Correction, error)
+	// FindStockLocks returns every lock, or only the locks of a stock when
+	// stockID is set
+	FindStockLocks(ctx context.Context, stockID uuid.UUID) ([]StockLock, error)
+}
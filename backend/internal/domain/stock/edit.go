@@ -0,0 +1,43 @@
+package stock
+
+import "time"
+
+// Edit overlays fields on a stock record; nil fields keep the record's value.
+type Edit struct {
+	Company    *string
+	Brokerage  *string
+	Action     *string
+	RatingFrom *Rating
+	RatingTo   *Rating
+	TargetFrom *float64
+	TargetTo   *float64
+	Time       *time.Time
+}
+
+// Apply sets the edited fields on the stock
+func (e Edit) Apply(s *Stock) {
+	if e.Company != nil {
+		s.Company = *e.Company
+	}
+	if e.Brokerage != nil {
+		s.Brokerage = *e.Brokerage
+	}
+	if e.Action != nil {
+		s.Action = *e.Action
+	}
+	if e.RatingFrom != nil {
+		s.Rating.From = *e.RatingFrom
+	}
+	if e.RatingTo != nil {
+		s.Rating.To = *e.RatingTo
+	}
+	if e.TargetFrom != nil {
+		s.Target.From.Amount = *e.TargetFrom
+	}
+	if e.TargetTo != nil {
+		s.Target.To.Amount = *e.TargetTo
+	}
+	if e.Time != nil {
+		s.Time = *e.Time
+	}
+}
@@ -1,7 +1,6 @@
 package stock
 
 import (
-	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -36,15 +35,42 @@ const (
 	Sell         Rating = "Sell"
 )
 
+// ratingLevels groups ratings into four levels, from negative (1) to very positive (4)
+var ratingLevels = map[Rating]int{
+	StrongBuy:  4,
+	Outperform: 4,
+	Overweight: 4,
+
+	Buy:      3,
+	Positive: 3,
+
+	Hold:          2,
+	Neutral:       2,
+	EqualWeight:   2,
+	MarketPerform: 2,
+
+	Underweight:  1,
+	Underperform: 1,
+	Sell:         1,
+}
+
+// Level returns the rating level, or 0 for an unknown rating
+func (r Rating) Level() int {
+	return ratingLevels[r]
+}
+
 type Stock struct {
 	ID        uuid.UUID
-	Ticker    string
+	Ticker    Ticker
 	Target    TargetPrice
 	Company   string
 	Action    string
 	Brokerage string
 	Rating    RatingChange
 	Time      time.Time
+	Source    string // Upstream provider that reported the action
+	// Classification is the company reference data, nil when none was imported
+	Classification *Classification
 }
 
 type TargetPrice struct {
@@ -57,15 +83,25 @@ type RatingChange struct {
 	To   Rating
 }
 
+// Direction is the rating level difference: positive for an upgrade, negative
+// for a downgrade and zero when either rating is unknown
+func (c RatingChange) Direction() int {
+	if c.From.Level() == 0 || c.To.Level() == 0 {
+		return 0
+	}
+	return c.To.Level() - c.From.Level()
+}
+
 func NewStock(ticker string, targetFrom, targetTo Money) (*Stock, error) {
-	if ticker == "" {
-		return nil, errors.New("ticker cannot be empty")
+	symbol, err := ParseTicker(ticker)
+	if err != nil {
+		return nil, err
 	}
 
 	now := time.Now()
 	return &Stock{
 		ID:     uuid.New(),
-		Ticker: ticker,
+		Ticker: symbol,
 		Target: TargetPrice{
 			From: targetFrom,
 			To:   targetTo,
@@ -74,116 +110,7 @@ func NewStock(ticker string, targetFrom, targetTo Money) (*Stock, error) {
 	}, nil
 }
 
-func (s *Stock) CalculateInvestmentScore() float64 {
-	var score float64
-
-	// Factor 1: Growth Potential (30%)
-	targetDiff := (s.Target.To.Amount - s.Target.From.Amount) / s.Target.From.Amount
-	growthScore := targetDiff * 0.3
-
-	// Factor 2: Broker Rating (25%)
-	var ratingScore float64
-	switch s.Rating.To {
-	case StrongBuy, Outperform, Overweight:
-		ratingScore = 0.25
-	case Buy, Positive:
-		ratingScore = 0.20
-	case Hold, Neutral, EqualWeight, MarketPerform:
-		ratingScore = 0.15
-	case Underweight, Underperform:
-		ratingScore = 0.05
-	case Sell:
-		ratingScore = 0
-	}
-
-	// Factor 3: Rating Improvement (15%)
-	var ratingImprovementScore float64
-	if s.Rating.From != s.Rating.To {
-		// Define the rating levels to calculate the improvement
-		ratingLevels := map[Rating]int{
-			// Very Positive (level 4)
-			StrongBuy:  4,
-			Outperform: 4,
-			Overweight: 4,
-
-			// Positive (level 3)
-			Buy:      3,
-			Positive: 3,
-
-			// Neutral (level 2)
-			Hold:          2,
-			Neutral:       2,
-			EqualWeight:   2,
-			MarketPerform: 2,
-
-			// Negative (level 1)
-			Underweight:  1,
-			Underperform: 1,
-			Sell:         1,
-		}
-
-		fromLevel := ratingLevels[s.Rating.From]
-		toLevel := ratingLevels[s.Rating.To]
-
-		// Calculate the improvement based on the levels
-		levelImprovement := toLevel - fromLevel
-
-		switch {
-		case levelImprovement >= 3:
-			ratingImprovementScore = 0.15 // Maximum improvement (e.g., from Sell to StrongBuy)
-		case levelImprovement == 2:
-			ratingImprovementScore = 0.12 // Significant improvement (e.g., from Sell to Buy)
-		case levelImprovement == 1:
-			ratingImprovementScore = 0.08 // Moderate improvement (e.g., from Sell to Hold)
-		case levelImprovement < 0:
-			ratingImprovementScore = 0.0 // No improvement, it's a downgrade
-		}
-	}
-
-	// Factor 4: Broker Reputation (20%)
-	var brokerageScore float64
-	switch s.Brokerage {
-	// Tier S - Global brokers of maximum prestige
-	case "The Goldman Sachs Group", "Morgan Stanley", "JPMorgan Chase & Co.", "Bank of America", "Citigroup":
-		brokerageScore = 0.20
-
-	// Tier A - High prestige brokers
-	case "Wells Fargo & Company", "UBS Group", "Deutsche Bank Aktiengesellschaft",
-		"Barclays", "Royal Bank of Canada", "HSBC", "BNP Paribas",
-		"BMO Capital Markets", "Mizuho", "Scotiabank":
-		brokerageScore = 0.15
-
-	// Tier B - Established and specialized brokers
-	case "Jefferies Financial Group", "Raymond James", "Evercore ISI",
-		"Piper Sandler", "TD Cowen", "Oppenheimer", "Stifel Nicolaus",
-		"Keefe, Bruyette & Woods", "Cantor Fitzgerald", "Truist Financial",
-		"Wedbush", "Robert W. Baird", "Sanford C. Bernstein", "CIBC",
-		"Macquarie", "Guggenheim", "TD Securities", "Susquehanna":
-		brokerageScore = 0.10
-
-	// Tier C - Boutique and regional brokers (y cualquier otro broker no listado)
-	default:
-		brokerageScore = 0.05
-	}
-
-	// Factor 5: Recommendation Timeliness (10%)
-	var timelinessScore float64
-	daysSinceUpdate := time.Since(s.Time).Hours() / 24
-	if daysSinceUpdate <= 7 {
-		timelinessScore = 0.1
-	} else if daysSinceUpdate <= 30 {
-		timelinessScore = 0.05
-	}
-
-	// Calculate final score
-	score = growthScore + ratingScore + ratingImprovementScore + brokerageScore + timelinessScore
-
-	// Normalize score between 0 and 1
-	if score > 1 {
-		score = 1
-	} else if score < 0 {
-		score = 0
-	}
-
-	return score
+// CalculateInvestmentScore scores the stock with the given rating half-life
+func (s *Stock) CalculateInvestmentScore(halfLife time.Duration) float64 {
+	return s.ExplainInvestmentScore(halfLife).Score
 }
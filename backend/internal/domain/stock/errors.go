@@ -23,6 +23,11 @@ var (
 		Message: "price must be greater than zero",
 	}
 
+	ErrInvalidCorrection = &DomainError{
+		Code:    "INVALID_CORRECTION",
+		Message: "manual correction has invalid fields",
+	}
+
 	ErrInvalidRating = &DomainError{
 		Code:    "INVALID_RATING",
 		Message: "rating must be one of: Buy, Sell, Hold, Neutral",
@@ -50,6 +55,76 @@ var (
 		Message: "stock not found in the system",
 	}
 
+	ErrSyncRunNotFound = &DomainError{
+		Code:    "SYNC_RUN_NOT_FOUND",
+		Message: "sync run not found in the system",
+	}
+
+	ErrSyncInProgress = &DomainError{
+		Code:    "SYNC_IN_PROGRESS",
+		Message: "a stock synchronization is already running",
+	}
+
+	ErrSyncLeaseLost = &DomainError{
+		Code:    "SYNC_LEASE_LOST",
+		Message: "the sync lease expired and was taken over",
+	}
+
+	ErrRejectedRecordNotFound = &DomainError{
+		Code:    "REJECTED_RECORD_NOT_FOUND",
+		Message: "rejected record not found in the system",
+	}
+
+	ErrRecordAlreadyResolved = &DomainError{
+		Code:    "RECORD_ALREADY_RESOLVED",
+		Message: "rejected record was already reprocessed or discarded",
+	}
+
+	ErrSectorNotFound = &DomainError{
+		Code:    "SECTOR_NOT_FOUND",
+		Message: "no stocks classified in the sector",
+	}
+
+	ErrPortfolioNotFound = &DomainError{
+		Code:    "PORTFOLIO_NOT_FOUND",
+		Message: "portfolio not found in the system",
+	}
+
+	ErrInvalidConstraints = &DomainError{
+		Code:    "INVALID_CONSTRAINTS",
+		Message: "portfolio constraints are out of range",
+	}
+
+	ErrEmptyPortfolio = &DomainError{
+		Code:    "EMPTY_PORTFOLIO",
+		Message: "no recommendation meets the portfolio constraints",
+	}
+
+	ErrStockNotLocked = &DomainError{
+		Code:    "STOCK_NOT_LOCKED",
+		Message: "stock has no manual correction to release",
+	}
+
+	ErrStockLocked = &DomainError{
+		Code:    "STOCK_LOCKED",
+		Message: "stock is locked by a manual correction",
+	}
+
+	ErrTickerTaken = &DomainError{
+		Code:    "TICKER_TAKEN",
+		Message: "another stock record already holds the ticker",
+	}
+
+	ErrUnrecordedOptions = &DomainError{
+		Code:    "UNRECORDED_OPTIONS",
+		Message: "score history is only recorded with the default max_age and half_life",
+	}
+
+	ErrSnapshotNotFound = &DomainError{
+		Code:    "SNAPSHOT_NOT_FOUND",
+		Message: "no analysis snapshot stored for the key",
+	}
+
 	ErrAnalysisNotPossible = &DomainError{
 		Code:    "ANALYSIS_NOT_POSSIBLE",
 		Message: "insufficient data to perform analysis",
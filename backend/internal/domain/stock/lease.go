@@ -0,0 +1,34 @@
+package stock
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SyncLeaseName is the lease every replica takes before running a sync
+const SyncLeaseName = "stock-sync"
+
+// SyncLease is a time-limited claim on a shared job. The holder keeps it alive
+// with heartbeats; once it expires another replica may take it over.
+type SyncLease struct {
+	Name       string
+	Holder     string
+	RunID      uuid.UUID
+	AcquiredAt time.Time
+	ExpiresAt  time.Time
+}
+
+// SyncInProgressError reports the lease of the sync that is already running
+type SyncInProgressError struct {
+	Lease *SyncLease
+}
+
+func (e *SyncInProgressError) Error() string {
+	return fmt.Sprintf("%s (run %s on %s)", ErrSyncInProgress.Error(), e.Lease.RunID, e.Lease.Holder)
+}
+
+func (e *SyncInProgressError) Unwrap() error {
+	return ErrSyncInProgress
+}
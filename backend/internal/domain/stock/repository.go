@@ -2,16 +2,152 @@ package stock
 
 import (
 	"context"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 type Repository interface {
+	// Save upserts the stock by ticker, or returns ErrStockLocked when a
+	// manual correction locks the ticker
 	Save(ctx context.Context, stock *Stock) error
-	FindByTicker(ctx context.Context, ticker string) (*Stock, error)
+	FindByTicker(ctx context.Context, ticker Ticker) (*Stock, error)
 	FindAll(ctx context.Context) ([]*Stock, error)
+	// Iterate calls fn for every stock, newest first, without loading them all
+	// in memory. Iteration stops at the first error returned by fn.
+	Iterate(ctx context.Context, fn func(*Stock) error) error
 	Update(ctx context.Context, stock *Stock) error
+	Ping(ctx context.Context) error
 	Close(ctx context.Context) error
 }
 
+// RatingActionRepository keeps every rating action seen by a sync, where the
+// stocks table only holds the latest action per ticker. Actions superseded by
+// a manual correction are kept but no longer returned.
+type RatingActionRepository interface {
+	// SaveRatingActions stores the actions, skipping ones already stored for
+	// the same ticker, brokerage and time and ones on tickers locked by a
+	// manual correction
+	SaveRatingActions(ctx context.Context, actions []*Stock) error
+	// FindRatingActions returns the actions on the tickers, newest first
+	FindRatingActions(ctx context.Context, tickers []Ticker) ([]*Stock, error)
+	// FindRatingActionsSince returns the actions on every ticker from the
+	// given time on, newest first
+	FindRatingActionsSince(ctx context.Context, since time.Time) ([]*Stock, error)
+}
+
+// SearchField names the field a search matched
+type SearchField string
+
+const (
+	SearchTicker    SearchField = "ticker"
+	SearchCompany   SearchField = "company"
+	SearchBrokerage SearchField = "brokerage"
+)
+
+// SearchHit is a stock found by a search, ranked from 0 to 1 on the field
+// that matched best
+type SearchHit struct {
+	Stock *Stock
+	Field SearchField
+	Rank  float64
+	// Brokerage is the best matching broker that rated the stock, which may
+	// differ from the one of its latest action; empty when none matched
+	Brokerage string
+}
+
+type SearchRepository interface {
+	// Search matches the query against tickers, company names and the
+	// brokerages of every rating action by prefix and by similarity, so small
+	// typos still match. Hits are returned best first.
+	Search(ctx context.Context, query string, limit int) ([]SearchHit, error)
+}
+
+// SyncState is the checkpoint kept per source between synchronizations
+type SyncState struct {
+	Source         string
+	LastEventTime  time.Time // Newest event stored from this source
+	LastFullSyncAt time.Time // Last sync that crawled the whole history
+	UpdatedAt      time.Time
+}
+
+type SyncStateRepository interface {
+	FindAllSyncStates(ctx context.Context) ([]SyncState, error)
+	SaveSyncState(ctx context.Context, state SyncState) error
+}
+
+type SyncRunRepository interface {
+	CreateSyncRun(ctx context.Context, run *SyncRun) error
+	// FinishSyncRun stores the outcome of the run along with the new and
+	// changed records; unchanged records are only counted in the summary
+	FinishSyncRun(ctx context.Context, run *SyncRun, changes []StockChange) error
+	FindSyncRun(ctx context.Context, id uuid.UUID) (*SyncRun, error)
+	FindSyncChanges(ctx context.Context, runID uuid.UUID) ([]StockChange, error)
+}
+
+// DataVersion identifies a state of the stored stock data. Every write that
+// changes stocks bumps it, so readers can tell whether derived data is current.
+type DataVersion struct {
+	Version   int64
+	UpdatedAt time.Time
+}
+
+type DataVersionRepository interface {
+	// CurrentDataVersion returns version zero until the first bump
+	CurrentDataVersion(ctx context.Context) (DataVersion, error)
+	BumpDataVersion(ctx context.Context) (DataVersion, error)
+}
+
+type SyncLeaseRepository interface {
+	// AcquireLease stores the lease unless another one with the same name has
+	// not expired yet, in which case it returns a *SyncInProgressError
+	AcquireLease(ctx context.Context, lease *SyncLease, ttl time.Duration) error
+	// RenewLease extends a held lease, or returns ErrSyncLeaseLost if it
+	// expired and was taken over
+	RenewLease(ctx context.Context, lease *SyncLease, ttl time.Duration) error
+	ReleaseLease(ctx context.Context, lease *SyncLease) error
+}
+
+// FetchOptions narrows what a provider has to crawl
+type FetchOptions struct {
+	// Since is the high-water mark: providers stop paging once they reach
+	// events older than it. The zero value crawls the whole history.
+	Since time.Time
+	// SinceBySource overrides Since for providers merged under a source name
+	SinceBySource map[string]time.Time
+}
+
+// SinceFor returns the high-water mark that applies to the named source
+func (o FetchOptions) SinceFor(source string) time.Time {
+	if since, ok := o.SinceBySource[source]; ok {
+		return since
+	}
+	return o.Since
+}
+
+type RejectedRecordRepository interface {
+	// SaveRejected quarantines the records a source has not already sent with
+	// the same payload, and returns how many were new
+	SaveRejected(ctx context.Context, records []RejectedRecord) (int, error)
+	// FindRejected lists records with the given status, newest first; an empty
+	// status lists all of them
+	FindRejected(ctx context.Context, status RejectedStatus, limit int) ([]RejectedRecord, error)
+	FindRejectedByID(ctx context.Context, id uuid.UUID) (*RejectedRecord, error)
+	UpdateRejected(ctx context.Context, record *RejectedRecord) error
+}
+
+// RecordParser turns a raw provider record back into a Stock, so quarantined
+// records can be reprocessed with the same rules as a sync
+type RecordParser interface {
+	ParseRecord(payload []byte) (*Stock, error)
+}
+
+// FetchResult holds the parsed stocks and the records that failed validation
+type FetchResult struct {
+	Stocks   []*Stock
+	Rejected []RejectedRecord
+}
+
 type StockAPIPort interface {
-	FetchStocks(ctx context.Context) ([]*Stock, error)
+	FetchStocks(ctx context.Context, opts FetchOptions) (*FetchResult, error)
 }
@@ -0,0 +1,214 @@
+package stock
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// DefaultRatingHalfLife is how long a rating action takes to lose half its weight
+const DefaultRatingHalfLife = 7 * 24 * time.Hour
+
+const (
+	FactorGrowthPotential   = "growth_potential"
+	FactorBrokerRating      = "broker_rating"
+	FactorRatingImprovement = "rating_improvement"
+	FactorBrokerReputation  = "broker_reputation"
+	FactorTimeliness        = "timeliness"
+)
+
+// ScoreFactor is one term of the investment score
+type ScoreFactor struct {
+	Name string
+	// Input describes what the factor looked at, e.g. the rating or broker
+	Input string
+	// RawValue is the numeric input the contribution is derived from: the
+	// target growth ratio, the rating level, the change in rating level, the
+	// broker tier (1 for tier S to 4 for tier C) or the days since the action
+	RawValue float64
+	// Weight is the share of the score the factor is designed to carry
+	Weight float64
+	// Decay is the time-decay multiplier already applied to the contribution,
+	// 1 for factors that do not age
+	Decay        float64
+	Contribution float64
+}
+
+// ScoreExplanation breaks the investment score down into its factors. The
+// score is the sum of the contributions clamped to [0, 1].
+type ScoreExplanation struct {
+	Factors  []ScoreFactor
+	RawScore float64
+	Score    float64
+	Clamped  bool
+}
+
+// ExplainInvestmentScore computes the investment score along with the
+// contribution of every factor. The rating signals decay exponentially with
+// the age of the action; a zero half-life disables the decay.
+func (s *Stock) ExplainInvestmentScore(halfLife time.Duration) ScoreExplanation {
+	decay := s.RatingDecay(halfLife)
+	factors := []ScoreFactor{
+		s.growthFactor(),
+		s.ratingFactor().decayed(decay),
+		s.ratingImprovementFactor().decayed(decay),
+		s.brokerReputationFactor(),
+		s.timelinessFactor(decay),
+	}
+
+	var raw float64
+	for _, factor := range factors {
+		raw += factor.Contribution
+	}
+
+	// Normalize score between 0 and 1
+	score := raw
+	if score > 1 {
+		score = 1
+	} else if score < 0 {
+		score = 0
+	}
+
+	return ScoreExplanation{
+		Factors:  factors,
+		RawScore: raw,
+		Score:    score,
+		Clamped:  score != raw,
+	}
+}
+
+// RatingDecay is the weight left to the rating action after its age, halving
+// every halfLife
+func (s *Stock) RatingDecay(halfLife time.Duration) float64 {
+	age := time.Since(s.Time)
+	if halfLife <= 0 || age <= 0 {
+		return 1
+	}
+	return math.Pow(0.5, float64(age)/float64(halfLife))
+}
+
+func (f ScoreFactor) decayed(decay float64) ScoreFactor {
+	f.Decay = decay
+	f.Contribution *= decay
+	return f
+}
+
+// Factor 1: Growth Potential (30%)
+func (s *Stock) growthFactor() ScoreFactor {
+	// Without a starting target there is no growth to measure
+	var targetDiff float64
+	if s.Target.From.Amount > 0 {
+		targetDiff = (s.Target.To.Amount - s.Target.From.Amount) / s.Target.From.Amount
+	}
+	return ScoreFactor{
+		Name:         FactorGrowthPotential,
+		Decay:        1,
+		Input:        fmt.Sprintf("target %.2f -> %.2f", s.Target.From.Amount, s.Target.To.Amount),
+		RawValue:     targetDiff,
+		Weight:       0.3,
+		Contribution: targetDiff * 0.3,
+	}
+}
+
+// Factor 2: Broker Rating (25%)
+func (s *Stock) ratingFactor() ScoreFactor {
+	var ratingScore float64
+	switch s.Rating.To {
+	case StrongBuy, Outperform, Overweight:
+		ratingScore = 0.25
+	case Buy, Positive:
+		ratingScore = 0.20
+	case Hold, Neutral, EqualWeight, MarketPerform:
+		ratingScore = 0.15
+	case Underweight, Underperform:
+		ratingScore = 0.05
+	case Sell:
+		ratingScore = 0
+	}
+
+	return ScoreFactor{
+		Name:         FactorBrokerRating,
+		Decay:        1,
+		Input:        string(s.Rating.To),
+		RawValue:     float64(s.Rating.To.Level()),
+		Weight:       0.25,
+		Contribution: ratingScore,
+	}
+}
+
+// Factor 3: Rating Improvement (15%)
+func (s *Stock) ratingImprovementFactor() ScoreFactor {
+	var ratingImprovementScore float64
+	levelImprovement := s.Rating.To.Level() - s.Rating.From.Level()
+	if s.Rating.From != s.Rating.To {
+		switch {
+		case levelImprovement >= 3:
+			ratingImprovementScore = 0.15 // Maximum improvement (e.g., from Sell to StrongBuy)
+		case levelImprovement == 2:
+			ratingImprovementScore = 0.12 // Significant improvement (e.g., from Sell to Buy)
+		case levelImprovement == 1:
+			ratingImprovementScore = 0.08 // Moderate improvement (e.g., from Sell to Hold)
+		case levelImprovement < 0:
+			ratingImprovementScore = 0.0 // No improvement, it's a downgrade
+		}
+	}
+
+	return ScoreFactor{
+		Name:         FactorRatingImprovement,
+		Decay:        1,
+		Input:        fmt.Sprintf("%s -> %s", s.Rating.From, s.Rating.To),
+		RawValue:     float64(levelImprovement),
+		Weight:       0.15,
+		Contribution: ratingImprovementScore,
+	}
+}
+
+// Factor 4: Broker Reputation (20%)
+func (s *Stock) brokerReputationFactor() ScoreFactor {
+	var brokerageScore float64
+	var tier float64
+	switch s.Brokerage {
+	// Tier S - Global brokers of maximum prestige
+	case "The Goldman Sachs Group", "Morgan Stanley", "JPMorgan Chase & Co.", "Bank of America", "Citigroup":
+		brokerageScore, tier = 0.20, 1
+
+	// Tier A - High prestige brokers
+	case "Wells Fargo & Company", "UBS Group", "Deutsche Bank Aktiengesellschaft",
+		"Barclays", "Royal Bank of Canada", "HSBC", "BNP Paribas",
+		"BMO Capital Markets", "Mizuho", "Scotiabank":
+		brokerageScore, tier = 0.15, 2
+
+	// Tier B - Established and specialized brokers
+	case "Jefferies Financial Group", "Raymond James", "Evercore ISI",
+		"Piper Sandler", "TD Cowen", "Oppenheimer", "Stifel Nicolaus",
+		"Keefe, Bruyette & Woods", "Cantor Fitzgerald", "Truist Financial",
+		"Wedbush", "Robert W. Baird", "Sanford C. Bernstein", "CIBC",
+		"Macquarie", "Guggenheim", "TD Securities", "Susquehanna":
+		brokerageScore, tier = 0.10, 3
+
+	// Tier C - Boutique and regional brokers (y cualquier otro broker no listado)
+	default:
+		brokerageScore, tier = 0.05, 4
+	}
+
+	return ScoreFactor{
+		Name:         FactorBrokerReputation,
+		Decay:        1,
+		Input:        s.Brokerage,
+		RawValue:     tier,
+		Weight:       0.20,
+		Contribution: brokerageScore,
+	}
+}
+
+// Factor 5: Recommendation Timeliness (10%), the full weight decayed by age
+func (s *Stock) timelinessFactor(decay float64) ScoreFactor {
+	return ScoreFactor{
+		Name:         FactorTimeliness,
+		Input:        s.Time.Format(time.RFC3339),
+		RawValue:     time.Since(s.Time).Hours() / 24,
+		Weight:       0.10,
+		Decay:        decay,
+		Contribution: 0.10 * decay,
+	}
+}
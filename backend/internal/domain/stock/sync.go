@@ -0,0 +1,179 @@
+package stock
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SyncStatus string
+
+const (
+	SyncRunning   SyncStatus = "running"
+	SyncSucceeded SyncStatus = "succeeded"
+	SyncFailed    SyncStatus = "failed"
+)
+
+// SyncRun records one synchronization and what it changed
+type SyncRun struct {
+	ID         uuid.UUID
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Status     SyncStatus
+	Full       bool
+	Fetched    int
+	Rejected   int
+	Summary    ChangeSummary
+	Error      string
+}
+
+func NewSyncRun(full bool) *SyncRun {
+	return &SyncRun{
+		ID:        uuid.New(),
+		StartedAt: time.Now(),
+		Status:    SyncRunning,
+		Full:      full,
+	}
+}
+
+type RejectedStatus string
+
+const (
+	RejectedPending     RejectedStatus = "pending"
+	RejectedReprocessed RejectedStatus = "reprocessed"
+	RejectedDiscarded   RejectedStatus = "discarded"
+)
+
+// RejectedRecord is a provider record that could not be turned into a Stock.
+// Syncs quarantine them and carry on with the valid records.
+type RejectedRecord struct {
+	ID         uuid.UUID
+	RunID      uuid.UUID
+	Source     string
+	Payload    []byte // Raw record as received, JSON encoded
+	Reason     string
+	Status     RejectedStatus
+	ReceivedAt time.Time
+	ResolvedAt time.Time
+}
+
+type ChangeKind string
+
+const (
+	ChangeNew       ChangeKind = "new"
+	ChangeUpdated   ChangeKind = "changed"
+	ChangeUnchanged ChangeKind = "unchanged"
+	// ChangeLocked is an incoming record skipped because its ticker was
+	// corrected by hand
+	ChangeLocked ChangeKind = "locked"
+)
+
+const (
+	FieldRatingTo  = "rating_to"
+	FieldTargetTo  = "target_to"
+	FieldBrokerage = "brokerage"
+)
+
+// FieldChange is the before and after value of one tracked field
+type FieldChange struct {
+	Field string
+	From  string
+	To    string
+}
+
+// StockChange classifies an incoming record against the stored one
+type StockChange struct {
+	Ticker Ticker
+	Kind   ChangeKind
+	Fields []FieldChange
+	// Direction is the rating level difference: positive for an upgrade,
+	// negative for a downgrade and zero when either rating is unknown
+	Direction int
+}
+
+// ChangeSummary counts the changes of a sync run
+type ChangeSummary struct {
+	New              int
+	Changed          int
+	Unchanged        int
+	Upgrades         int
+	Downgrades       int
+	TargetChanges    int
+	BrokerageChanges int
+	Locked           int
+}
+
+// Add counts a change in the summary
+func (s *ChangeSummary) Add(change StockChange) {
+	switch change.Kind {
+	case ChangeNew:
+		s.New++
+		return
+	case ChangeUnchanged:
+		s.Unchanged++
+		return
+	case ChangeLocked:
+		s.Locked++
+		return
+	}
+
+	s.Changed++
+	switch {
+	case change.Direction > 0:
+		s.Upgrades++
+	case change.Direction < 0:
+		s.Downgrades++
+	}
+	for _, field := range change.Fields {
+		switch field.Field {
+		case FieldTargetTo:
+			s.TargetChanges++
+		case FieldBrokerage:
+			s.BrokerageChanges++
+		}
+	}
+}
+
+// DiffStock compares an incoming record with the stored one for the same
+// ticker. stored is nil when the ticker has never been synchronized.
+func DiffStock(stored, incoming *Stock) StockChange {
+	change := StockChange{Ticker: incoming.Ticker}
+	if stored == nil {
+		change.Kind = ChangeNew
+		return change
+	}
+
+	if stored.Rating.To != incoming.Rating.To {
+		change.Fields = append(change.Fields, FieldChange{
+			Field: FieldRatingTo,
+			From:  string(stored.Rating.To),
+			To:    string(incoming.Rating.To),
+		})
+		change.Direction = RatingChange{From: stored.Rating.To, To: incoming.Rating.To}.Direction()
+	}
+	if stored.Target.To.Amount != incoming.Target.To.Amount {
+		change.Fields = append(change.Fields, FieldChange{
+			Field: FieldTargetTo,
+			From:  formatAmount(stored.Target.To.Amount),
+			To:    formatAmount(incoming.Target.To.Amount),
+		})
+	}
+	if stored.Brokerage != incoming.Brokerage {
+		change.Fields = append(change.Fields, FieldChange{
+			Field: FieldBrokerage,
+			From:  stored.Brokerage,
+			To:    incoming.Brokerage,
+		})
+	}
+
+	change.Kind = ChangeUnchanged
+	if len(change.Fields) > 0 {
+		change.Kind = ChangeUpdated
+	}
+	return change
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
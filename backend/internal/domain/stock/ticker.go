@@ -0,0 +1,28 @@
+package stock
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Ticker is a normalized stock symbol: upper case, with an optional share
+// class or exchange suffix such as BRK.B, RDS-A or SHOP.TO
+type Ticker string
+
+// tickerPattern is a root symbol followed by up to two class or exchange
+// suffixes
+var tickerPattern = regexp.MustCompile(`^[A-Z0-9]{1,10}([.\-][A-Z0-9]{1,4}){0,2}$`)
+
+// ParseTicker trims and upper-cases the symbol, or fails with
+// ErrInvalidTicker when it is not a ticker
+func ParseTicker(symbol string) (Ticker, error) {
+	normalized := strings.ToUpper(strings.TrimSpace(symbol))
+	if !tickerPattern.MatchString(normalized) {
+		return "", ErrInvalidTicker
+	}
+	return Ticker(normalized), nil
+}
+
+func (t Ticker) String() string {
+	return string(t)
+}
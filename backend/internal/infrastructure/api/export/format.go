@@ -0,0 +1,60 @@
+package export
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+type Format string
+
+const (
+	FormatJSON   Format = "json"
+	FormatCSV    Format = "csv"
+	FormatXLSX   Format = "xlsx"
+	FormatNDJSON Format = "ndjson"
+)
+
+const (
+	MediaTypeJSON   = "application/json"
+	MediaTypeCSV    = "text/csv"
+	MediaTypeXLSX   = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	MediaTypeNDJSON = "application/x-ndjson"
+)
+
+var mediaTypes = map[string]Format{
+	MediaTypeJSON:   FormatJSON,
+	MediaTypeCSV:    FormatCSV,
+	MediaTypeXLSX:   FormatXLSX,
+	MediaTypeNDJSON: FormatNDJSON,
+}
+
+// Negotiate picks the response format from the format query parameter, then
+// from the Accept header. Unknown Accept values fall back to JSON.
+func Negotiate(r *http.Request) (Format, error) {
+	if value := r.URL.Query().Get("format"); value != "" {
+		switch format := Format(strings.ToLower(value)); format {
+		case FormatJSON, FormatCSV, FormatXLSX, FormatNDJSON:
+			return format, nil
+		default:
+			return "", fmt.Errorf("unsupported format %q", value)
+		}
+	}
+
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+		if format, ok := mediaTypes[mediaType]; ok {
+			return format, nil
+		}
+	}
+	return FormatJSON, nil
+}
+
+// IsStreamed reports whether the format is written row by row by a Writer
+func (f Format) IsStreamed() bool {
+	return f != FormatJSON
+}
@@ -0,0 +1,229 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// flushEvery is the number of rows written between flushes to the client
+const flushEvery = 100
+
+// Row is a record that can be flattened into export columns
+type Row interface {
+	ExportValues() []interface{}
+}
+
+// Writer streams rows to the response in a given format. Nothing is written
+// until the first row or Close, so an error before that point can still be
+// reported with a regular error response.
+type Writer struct {
+	w       http.ResponseWriter
+	format  Format
+	name    string
+	columns []string
+	rows    rowWriter
+}
+
+type rowWriter interface {
+	Write(row Row) error
+	Close() error
+}
+
+// NewWriter returns a writer for the format. name is used for the download
+// file name and spreadsheet tab.
+func NewWriter(w http.ResponseWriter, format Format, name string, columns []string) (*Writer, error) {
+	if !format.IsStreamed() {
+		return nil, fmt.Errorf("format %q cannot be streamed", format)
+	}
+	return &Writer{w: w, format: format, name: name, columns: columns}, nil
+}
+
+// Started reports whether headers have been sent to the client
+func (ew *Writer) Started() bool {
+	return ew.rows != nil
+}
+
+func (ew *Writer) Write(row Row) error {
+	if err := ew.start(); err != nil {
+		return err
+	}
+	return ew.rows.Write(row)
+}
+
+func (ew *Writer) Close() error {
+	if err := ew.start(); err != nil {
+		return err
+	}
+	return ew.rows.Close()
+}
+
+func (ew *Writer) start() error {
+	if ew.rows != nil {
+		return nil
+	}
+
+	var err error
+	switch ew.format {
+	case FormatCSV:
+		setHeaders(ew.w, MediaTypeCSV, ew.name+".csv")
+		ew.rows, err = newCSVWriter(ew.w, ew.columns)
+	case FormatNDJSON:
+		setHeaders(ew.w, MediaTypeNDJSON, ew.name+".ndjson")
+		ew.rows = &ndjsonWriter{encoder: json.NewEncoder(ew.w), rc: http.NewResponseController(ew.w)}
+	case FormatXLSX:
+		setHeaders(ew.w, MediaTypeXLSX, ew.name+".xlsx")
+		ew.rows, err = newXLSXWriter(ew.w, ew.name, ew.columns)
+	}
+	return err
+}
+
+func setHeaders(w http.ResponseWriter, contentType, fileName string) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+}
+
+type csvWriter struct {
+	csv  *csv.Writer
+	rc   *http.ResponseController
+	rows int
+}
+
+func newCSVWriter(w http.ResponseWriter, columns []string) (*csvWriter, error) {
+	cw := &csvWriter{csv: csv.NewWriter(w), rc: http.NewResponseController(w)}
+	if err := cw.csv.Write(columns); err != nil {
+		return nil, fmt.Errorf("error writing csv header: %w", err)
+	}
+	return cw, nil
+}
+
+func (cw *csvWriter) Write(row Row) error {
+	values := row.ExportValues()
+	record := make([]string, len(values))
+	for i, value := range values {
+		record[i] = formatValue(value)
+	}
+	if err := cw.csv.Write(record); err != nil {
+		return fmt.Errorf("error writing csv row: %w", err)
+	}
+
+	cw.rows++
+	if cw.rows%flushEvery == 0 {
+		cw.csv.Flush()
+		cw.rc.Flush()
+	}
+	return cw.csv.Error()
+}
+
+func (cw *csvWriter) Close() error {
+	cw.csv.Flush()
+	return cw.csv.Error()
+}
+
+type ndjsonWriter struct {
+	encoder *json.Encoder
+	rc      *http.ResponseController
+	rows    int
+}
+
+func (nw *ndjsonWriter) Write(row Row) error {
+	// Encode terminates every value with a newline
+	if err := nw.encoder.Encode(row); err != nil {
+		return fmt.Errorf("error writing ndjson row: %w", err)
+	}
+
+	nw.rows++
+	if nw.rows%flushEvery == 0 {
+		nw.rc.Flush()
+	}
+	return nil
+}
+
+func (nw *ndjsonWriter) Close() error {
+	return nil
+}
+
+// xlsxWriter uses the excelize stream writer so rows are spilled to a
+// temporary file instead of being held in memory
+type xlsxWriter struct {
+	w      http.ResponseWriter
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+func newXLSXWriter(w http.ResponseWriter, sheet string, columns []string) (*xlsxWriter, error) {
+	file := excelize.NewFile()
+	if err := file.SetSheetName("Sheet1", sheet); err != nil {
+		return nil, fmt.Errorf("error naming sheet: %w", err)
+	}
+	stream, err := file.NewStreamWriter(sheet)
+	if err != nil {
+		return nil, fmt.Errorf("error creating xlsx stream: %w", err)
+	}
+
+	xw := &xlsxWriter{w: w, file: file, stream: stream}
+	header := make([]interface{}, len(columns))
+	for i, column := range columns {
+		header[i] = column
+	}
+	if err := xw.writeRow(header); err != nil {
+		return nil, err
+	}
+	return xw, nil
+}
+
+func (xw *xlsxWriter) Write(row Row) error {
+	values := row.ExportValues()
+	for i, value := range values {
+		// Spreadsheets have no native timestamp type with zone information
+		if t, ok := value.(time.Time); ok {
+			values[i] = t.UTC().Format(time.RFC3339)
+		}
+	}
+	return xw.writeRow(values)
+}
+
+func (xw *xlsxWriter) writeRow(values []interface{}) error {
+	xw.row++
+	cell, err := excelize.CoordinatesToCellName(1, xw.row)
+	if err != nil {
+		return err
+	}
+	if err := xw.stream.SetRow(cell, values); err != nil {
+		return fmt.Errorf("error writing xlsx row: %w", err)
+	}
+	return nil
+}
+
+func (xw *xlsxWriter) Close() error {
+	defer xw.file.Close()
+
+	if err := xw.stream.Flush(); err != nil {
+		return fmt.Errorf("error flushing xlsx stream: %w", err)
+	}
+	if err := xw.file.Write(xw.w); err != nil {
+		return fmt.Errorf("error writing xlsx file: %w", err)
+	}
+	return nil
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -2,11 +2,20 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
 	"stockapi/internal/application/dto"
 	"stockapi/internal/application/services"
+	"stockapi/internal/domain/analysis"
+	"stockapi/internal/domain/stock"
+	"stockapi/internal/infrastructure/api/export"
+	"time"
 )
 
+// defaultHistoryRange is the score history returned without a from parameter
+const defaultHistoryRange = 30 * 24 * time.Hour
+
 type AnalysisHandler struct {
 	analysisService *services.AnalysisApplicationService
 }
@@ -20,23 +29,214 @@ func NewAnalysisHandler(service *services.AnalysisApplicationService) *AnalysisH
 func (h *AnalysisHandler) HandleAnalysis() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+
+		format, err := export.Negotiate(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		opts, err := h.analysisOptions(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		includeExcluded, err := boolQuery(r, "include_excluded")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if format.IsStreamed() {
+			h.exportAnalyses(w, r, format, opts)
 			return
 		}
 
 		ctx := r.Context()
-		analyses, err := h.analysisService.AnalyzeAllStocks(ctx)
+		snapshot, err := h.analysisService.AnalyzeAllStocks(ctx, opts)
 		if err != nil {
-			http.Error(w, "Error analyzing stocks: "+err.Error(), http.StatusInternalServerError)
+			writeError(w, http.StatusInternalServerError, "Error analyzing stocks: "+err.Error())
 			return
 		}
 
-		analysisResponses := make([]dto.AnalysisResponse, len(analyses))
-		for i, analysis := range analyses {
+		analysisResponses := make([]dto.AnalysisResponse, len(snapshot.Analyses))
+		for i, analysis := range snapshot.Analyses {
 			analysisResponses[i] = dto.ToAnalysisResponse(analysis)
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(analysisResponses)
+		// The bare list stays the default body for existing clients
+		var body interface{} = analysisResponses
+		if includeExcluded {
+			excluded := make([]dto.ExcludedStockResponse, len(snapshot.Excluded))
+			for i, exclusion := range snapshot.Excluded {
+				excluded[i] = dto.ToExcludedStockResponse(exclusion)
+			}
+			body = dto.RecommendationsResponse{
+				Recommendations: analysisResponses,
+				Excluded:        excluded,
+			}
+		}
+
+		// The list also changes when stocks go stale, so it is validated by content
+		etag, err := contentETag(body)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "Error encoding recommendations: "+err.Error())
+			return
+		}
+		if setValidators(w, r, etag, snapshot.ComputedAt) {
+			return
+		}
+
+		writeJSON(w, http.StatusOK, body)
+	}
+}
+
+func (h *AnalysisHandler) HandleScoreHistory() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ticker, err := symbolVar(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		from, err := timeQuery(r, "from")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		to, err := timeQuery(r, "to")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if to.IsZero() {
+			to = time.Now()
+		} else if len(r.URL.Query().Get("to")) == len(time.DateOnly) {
+			// A bare date includes the whole day
+			to = to.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		if from.IsZero() {
+			from = to.Add(-defaultHistoryRange)
+		}
+		if from.After(to) {
+			writeError(w, http.StatusBadRequest, "from must not be after to")
+			return
+		}
+
+		history, err := h.analysisService.GetScoreHistory(r.Context(), ticker, from, to)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "Error fetching score history: "+err.Error())
+			return
+		}
+
+		writeJSON(w, http.StatusOK, dto.ToScoreHistoryResponse(history))
+	}
+}
+
+func (h *AnalysisHandler) HandleExplain() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ticker, err := symbolVar(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		opts, err := h.analysisOptions(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		explanation, err := h.analysisService.ExplainStock(r.Context(), ticker, opts)
+		if errors.Is(err, stock.ErrStockNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "Error explaining score: "+err.Error())
+			return
+		}
+
+		writeJSON(w, http.StatusOK, dto.ToScoreExplanationResponse(explanation))
+	}
+}
+
+func (h *AnalysisHandler) HandleSimulate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		opts, err := h.analysisOptions(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		var request dto.SimulateRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+			return
+		}
+		if request.TargetFrom != nil && *request.TargetFrom < 0 || request.TargetTo != nil && *request.TargetTo < 0 {
+			writeError(w, http.StatusBadRequest, "Target prices cannot be negative")
+			return
+		}
+
+		simulation, err := h.analysisService.Simulate(r.Context(), request.ToScenario(), opts)
+		if errors.Is(err, stock.ErrInvalidTicker) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "Error simulating score: "+err.Error())
+			return
+		}
+
+		writeJSON(w, http.StatusOK, dto.ToSimulationResponse(simulation))
+	}
+}
+
+// exportAnalyses streams recommendations as they are scored. Rows follow
+// repository order, so consumers sort by the score column.
+func (h *AnalysisHandler) exportAnalyses(w http.ResponseWriter, r *http.Request, format export.Format, opts analysis.Options) {
+	clearWriteDeadline(w)
+	writer, err := export.NewWriter(w, format, "recommendations", dto.AnalysisExportColumns)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Error exporting recommendations: "+err.Error())
+		return
+	}
+
+	err = h.analysisService.StreamAnalyses(r.Context(), opts, func(a analysis.StockAnalysis) error {
+		return writer.Write(dto.ToAnalysisResponse(a))
+	})
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil && !writer.Started() {
+		writeError(w, http.StatusInternalServerError, "Error exporting recommendations: "+err.Error())
+		return
+	}
+	if err != nil {
+		// Headers are already sent, so the truncated body is the only signal left
+		log.Printf("error exporting recommendations as %s: %v", format, err)
+	}
+}
+
+// analysisOptions overrides the configured analysis options with the max_age,
+// half_life and include_stale query parameters
+func (h *AnalysisHandler) analysisOptions(r *http.Request) (analysis.Options, error) {
+	return analysisOptions(r, h.analysisService.DefaultOptions())
+}
+
+func analysisOptions(r *http.Request, opts analysis.Options) (analysis.Options, error) {
+	var err error
+	if opts.StalenessWindow, err = durationQuery(r, "max_age", opts.StalenessWindow); err != nil {
+		return opts, err
+	}
+	if opts.HalfLife, err = durationQuery(r, "half_life", opts.HalfLife); err != nil {
+		return opts, err
+	}
+	if r.URL.Query().Has("include_stale") {
+		if opts.IncludeStale, err = boolQuery(r, "include_stale"); err != nil {
+			return opts, err
+		}
 	}
+	return opts, nil
 }
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net/http"
+	"stockapi/internal/application/dto"
+	"stockapi/internal/application/services"
+	"stockapi/internal/domain/analysis"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultAnomalyLimit = 100
+	maxAnomalyLimit     = 1000
+
+	// defaultAnomalyWindow is how far back anomalies are reported unless the
+	// request sets since
+	defaultAnomalyWindow = 7 * 24 * time.Hour
+	// maxAnomalyWindow bounds since, which sets how much history is scanned
+	maxAnomalyWindow = 90 * 24 * time.Hour
+)
+
+type AnomalyHandler struct {
+	anomalyService *services.AnomalyService
+}
+
+func NewAnomalyHandler(service *services.AnomalyService) *AnomalyHandler {
+	return &AnomalyHandler{
+		anomalyService: service,
+	}
+}
+
+func (h *AnomalyHandler) HandleAnomalies() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := services.AnomalyQuery{
+			Kind:  analysis.AnomalyKind(r.URL.Query().Get("kind")),
+			Limit: defaultAnomalyLimit,
+		}
+		if query.Kind != "" && !query.Kind.Valid() {
+			writeError(w, http.StatusBadRequest, "Invalid kind parameter: "+string(query.Kind))
+			return
+		}
+
+		ticker, err := tickerQuery(r, "ticker")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		query.Ticker = ticker
+
+		since, err := timeQuery(r, "since")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		now := time.Now()
+		if since.IsZero() {
+			since = now.Add(-defaultAnomalyWindow)
+		}
+		if since.Before(now.Add(-maxAnomalyWindow)) || since.After(now) {
+			writeError(w, http.StatusBadRequest, "Invalid since parameter: must be within the last "+strconv.Itoa(int(maxAnomalyWindow/(24*time.Hour)))+" days")
+			return
+		}
+		query.Since = since
+
+		if value := r.URL.Query().Get("limit"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 1 || parsed > maxAnomalyLimit {
+				writeError(w, http.StatusBadRequest, "Invalid limit parameter: "+value)
+				return
+			}
+			query.Limit = parsed
+		}
+
+		anomalies, err := h.anomalyService.DetectAnomalies(r.Context(), query)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "Error detecting anomalies: "+err.Error())
+			return
+		}
+
+		writeJSON(w, http.StatusOK, dto.ToAnomaliesResponse(since, anomalies))
+	}
+}
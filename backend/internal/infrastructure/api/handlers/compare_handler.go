@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"stockapi/internal/application/dto"
+	"stockapi/internal/application/services"
+	"stockapi/internal/domain/stock"
+	"strings"
+	"time"
+)
+
+// maxCompareTickers bounds how many names a comparison lines up
+const maxCompareTickers = 10
+
+type CompareHandler struct {
+	analysisService   *services.AnalysisApplicationService
+	comparisonService *services.ComparisonService
+}
+
+func NewCompareHandler(analysisService *services.AnalysisApplicationService, comparisonService *services.ComparisonService) *CompareHandler {
+	return &CompareHandler{
+		analysisService:   analysisService,
+		comparisonService: comparisonService,
+	}
+}
+
+func (h *CompareHandler) HandleCompare() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tickers, err := tickersQuery(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		opts, err := analysisOptions(r, h.analysisService.DefaultOptions())
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		to := time.Now()
+		from, err := timeQuery(r, "from")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if from.IsZero() {
+			from = to.Add(-defaultHistoryRange)
+		}
+		if from.After(to) {
+			writeError(w, http.StatusBadRequest, "from must not be in the future")
+			return
+		}
+
+		comparison, err := h.comparisonService.Compare(r.Context(), tickers, from, to, opts)
+		if errors.Is(err, stock.ErrUnrecordedOptions) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if errors.Is(err, stock.ErrStockNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "Error comparing stocks: "+err.Error())
+			return
+		}
+
+		writeJSON(w, http.StatusOK, dto.ToComparisonResponse(comparison))
+	}
+}
+
+// tickersQuery reads the comma separated tickers parameter, normalized and
+// without duplicates
+func tickersQuery(r *http.Request) ([]stock.Ticker, error) {
+	var tickers []stock.Ticker
+	seen := make(map[stock.Ticker]bool)
+	for _, value := range strings.Split(r.URL.Query().Get("tickers"), ",") {
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+		ticker, err := stock.ParseTicker(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ticker in tickers parameter: %q", value)
+		}
+		if seen[ticker] {
+			continue
+		}
+		seen[ticker] = true
+		tickers = append(tickers, ticker)
+	}
+
+	if len(tickers) < 2 || len(tickers) > maxCompareTickers {
+		return nil, fmt.Errorf("tickers must list between 2 and %d distinct tickers", maxCompareTickers)
+	}
+	return tickers, nil
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"stockapi/internal/application/dto"
+	"stockapi/internal/application/services"
+	"stockapi/internal/domain/stock"
+	"stockapi/internal/infrastructure/api/middleware"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultCorrectionLimit = 100
+	maxCorrectionLimit     = 1000
+)
+
+type CorrectionHandler struct {
+	correctionService *services.CorrectionService
+}
+
+func NewCorrectionHandler(service *services.CorrectionService) *CorrectionHandler {
+	return &CorrectionHandler{
+		correctionService: service,
+	}
+}
+
+func (h *CorrectionHandler) HandleStockRecord() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			h.putStock(w, r)
+		case http.MethodPatch:
+			h.patchStock(w, r)
+		case http.MethodDelete:
+			h.deleteStock(w, r)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+	}
+}
+
+func (h *CorrectionHandler) putStock(w http.ResponseWriter, r *http.Request) {
+	id, edit, ok := decodeStockEdit(w, r)
+	if !ok {
+		return
+	}
+
+	stk, created, err := h.correctionService.Put(r.Context(), id, edit, middleware.AdminFromContext(r.Context()))
+	if err != nil {
+		writeCorrectionError(w, err)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	writeJSON(w, status, dto.ToStockResponse(stk))
+}
+
+func (h *CorrectionHandler) patchStock(w http.ResponseWriter, r *http.Request) {
+	id, edit, ok := decodeStockEdit(w, r)
+	if !ok {
+		return
+	}
+
+	stk, err := h.correctionService.Patch(r.Context(), id, edit, middleware.AdminFromContext(r.Context()))
+	if err != nil {
+		writeCorrectionError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, dto.ToStockResponse(stk))
+}
+
+func (h *CorrectionHandler) deleteStock(w http.ResponseWriter, r *http.Request) {
+	id, ok := stockIDVar(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.correctionService.Delete(r.Context(), id, middleware.AdminFromContext(r.Context())); err != nil {
+		writeCorrectionError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *CorrectionHandler) HandleRelease() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := stockIDVar(w, r)
+		if !ok {
+			return
+		}
+
+		released, err := h.correctionService.Release(r.Context(), id, middleware.AdminFromContext(r.Context()))
+		if err != nil {
+			writeCorrectionError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, dto.ToReleaseResponse(id.String(), released))
+	}
+}
+
+func (h *CorrectionHandler) HandleCorrections() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := stockIDVar(w, r)
+		if !ok {
+			return
+		}
+
+		limit := defaultCorrectionLimit
+		if value := r.URL.Query().Get("limit"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 1 || parsed > maxCorrectionLimit {
+				writeError(w, http.StatusBadRequest, "Invalid limit parameter: "+value)
+				return
+			}
+			limit = parsed
+		}
+
+		history, err := h.correctionService.History(r.Context(), id, limit)
+		if err != nil {
+			writeCorrectionError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, dto.ToCorrectionHistoryResponse(history))
+	}
+}
+
+func stockIDVar(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid stock id")
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func decodeStockEdit(w http.ResponseWriter, r *http.Request) (uuid.UUID, services.StockEdit, bool) {
+	id, ok := stockIDVar(w, r)
+	if !ok {
+		return uuid.Nil, services.StockEdit{}, false
+	}
+
+	var request dto.StockEditRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return uuid.Nil, services.StockEdit{}, false
+	}
+	return id, request.ToStockEdit(), true
+}
+
+func writeCorrectionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, stock.ErrStockNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, stock.ErrInvalidTicker), errors.Is(err, stock.ErrInvalidCorrection):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, stock.ErrTickerTaken), errors.Is(err, stock.ErrStockNotLocked):
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, "Error correcting stock: "+err.Error())
+	}
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http"
+	"stockapi/internal/infrastructure/api/openapi"
+)
+
+type DocsHandler struct{}
+
+func NewDocsHandler() *DocsHandler {
+	return &DocsHandler{}
+}
+
+func (h *DocsHandler) HandleSpec() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(ContentType, ApplicationJSON)
+		w.Write(openapi.SpecJSON())
+	}
+}
+
+// HandleAssets serves the Swagger UI files under /api/docs/
+func (h *DocsHandler) HandleAssets() http.Handler {
+	return http.StripPrefix("/api/docs/", http.FileServerFS(openapi.DocsAssets()))
+}
+
+func (h *DocsHandler) HandleDocs() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(ContentType, "text/html; charset=utf-8")
+		w.Write(openapi.DocsHTML())
+	}
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+	"stockapi/internal/application/dto"
+	"stockapi/internal/application/services"
+)
+
+type HealthHandler struct {
+	healthService *services.HealthService
+}
+
+func NewHealthHandler(service *services.HealthService) *HealthHandler {
+	return &HealthHandler{
+		healthService: service,
+	}
+}
+
+// HandleLiveness only reports that the process is up and serving requests
+func (h *HealthHandler) HandleLiveness() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": services.StatusOK})
+	}
+}
+
+// HandleReadiness returns 503 while a critical dependency is unreachable
+func (h *HealthHandler) HandleReadiness() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := h.healthService.Readiness(r.Context())
+
+		status := http.StatusOK
+		if report.Status == services.StatusUnavailable {
+			status = http.StatusServiceUnavailable
+		}
+		writeJSON(w, status, dto.ToReadinessResponse(report))
+	}
+}
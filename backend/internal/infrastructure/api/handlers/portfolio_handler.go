@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"stockapi/internal/application/dto"
+	"stockapi/internal/application/services"
+	"stockapi/internal/domain/portfolio"
+	"stockapi/internal/domain/stock"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultPortfolioLimit = 50
+	maxPortfolioLimit     = 500
+
+	// MaxHoldingsUpload bounds the size of an uploaded holdings file
+	MaxHoldingsUpload = 10 << 20
+)
+
+type PortfolioHandler struct {
+	portfolioService *services.PortfolioService
+}
+
+func NewPortfolioHandler(service *services.PortfolioService) *PortfolioHandler {
+	return &PortfolioHandler{
+		portfolioService: service,
+	}
+}
+
+func (h *PortfolioHandler) HandleConstruct() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request dto.ConstructPortfolioRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+			writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+			return
+		}
+
+		built, err := h.portfolioService.Construct(r.Context(), request.Name, request.ToConstraints())
+		if err != nil {
+			writePortfolioError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, dto.ToPortfolioResponse(built))
+	}
+}
+
+func (h *PortfolioHandler) HandlePortfolios() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit := defaultPortfolioLimit
+		if value := r.URL.Query().Get("limit"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 1 || parsed > maxPortfolioLimit {
+				writeError(w, http.StatusBadRequest, "Invalid limit parameter: "+value)
+				return
+			}
+			limit = parsed
+		}
+
+		portfolios, err := h.portfolioService.ListPortfolios(r.Context(), limit)
+		if err != nil {
+			writePortfolioError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, dto.ToPortfoliosResponse(portfolios))
+	}
+}
+
+func (h *PortfolioHandler) HandlePortfolio() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := uuid.Parse(mux.Vars(r)["id"])
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid portfolio id")
+			return
+		}
+
+		found, err := h.portfolioService.GetPortfolio(r.Context(), id)
+		if err != nil {
+			writePortfolioError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, dto.ToPortfolioResponse(found))
+	}
+}
+
+// HandleUploadHoldings stores the positions of a CSV file or a JSON document
+// as a new holdings portfolio
+func (h *PortfolioHandler) HandleUploadHoldings() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body := http.MaxBytesReader(w, r.Body, MaxHoldingsUpload)
+
+		var uploaded *portfolio.Portfolio
+		var err error
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "text/csv") {
+			uploaded, err = h.portfolioService.ImportHoldingsCSV(r.Context(), r.URL.Query().Get("name"), body)
+		} else {
+			var request dto.UploadHoldingsRequest
+			if err := json.NewDecoder(body).Decode(&request); err != nil {
+				if errors.As(err, new(*http.MaxBytesError)) {
+					writeUploadError(w, err)
+					return
+				}
+				writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+				return
+			}
+			uploaded, err = h.portfolioService.UploadHoldings(r.Context(), request.Name, request.ToHoldings())
+		}
+		if err != nil {
+			writeUploadError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, dto.ToPortfolioResponse(uploaded))
+	}
+}
+
+// HandleReview reports the latest broker ratings, recent downgrades and risk
+// flags of every holding of a portfolio
+func (h *PortfolioHandler) HandleReview() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := uuid.Parse(mux.Vars(r)["id"])
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid portfolio id")
+			return
+		}
+		since, err := timeQuery(r, "since")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if since.IsZero() {
+			since = time.Now().Add(-portfolio.DefaultReviewWindow)
+		}
+
+		review, err := h.portfolioService.Review(r.Context(), id, since)
+		if err != nil {
+			writePortfolioError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, dto.ToPortfolioReviewResponse(review))
+	}
+}
+
+func writeUploadError(w http.ResponseWriter, err error) {
+	var importErr *services.ImportError
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &importErr):
+		writeJSON(w, http.StatusUnprocessableEntity, dto.ToImportErrorResponse(importErr))
+	case errors.As(err, &tooLarge):
+		writeError(w, http.StatusRequestEntityTooLarge, "Holdings file is too large")
+	default:
+		writeError(w, http.StatusInternalServerError, "Error uploading holdings: "+err.Error())
+	}
+}
+
+func writePortfolioError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, stock.ErrInvalidConstraints):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, stock.ErrPortfolioNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, stock.ErrEmptyPortfolio):
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, "Error processing portfolio: "+err.Error())
+	}
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"stockapi/internal/application/dto"
+	"stockapi/internal/application/services"
+	"stockapi/internal/domain/stock"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultRejectedLimit = 100
+	maxRejectedLimit     = 1000
+)
+
+type QuarantineHandler struct {
+	quarantineService *services.QuarantineService
+}
+
+func NewQuarantineHandler(service *services.QuarantineService) *QuarantineHandler {
+	return &QuarantineHandler{
+		quarantineService: service,
+	}
+}
+
+func (h *QuarantineHandler) HandleRejectedRecords() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status := stock.RejectedStatus(r.URL.Query().Get("status"))
+		switch status {
+		case "":
+			status = stock.RejectedPending
+		case "all":
+			status = ""
+		case stock.RejectedPending, stock.RejectedReprocessed, stock.RejectedDiscarded:
+		default:
+			writeError(w, http.StatusBadRequest, "Invalid status parameter: "+string(status))
+			return
+		}
+
+		limit := defaultRejectedLimit
+		if value := r.URL.Query().Get("limit"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 1 || parsed > maxRejectedLimit {
+				writeError(w, http.StatusBadRequest, "Invalid limit parameter: "+value)
+				return
+			}
+			limit = parsed
+		}
+
+		records, err := h.quarantineService.ListRejected(r.Context(), status, limit)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "Error fetching rejected records: "+err.Error())
+			return
+		}
+
+		writeJSON(w, http.StatusOK, dto.ToRejectedRecordsResponse(records))
+	}
+}
+
+func (h *QuarantineHandler) HandleReprocess() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := uuid.Parse(mux.Vars(r)["id"])
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid rejected record id")
+			return
+		}
+
+		var request dto.ReprocessRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+			writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+			return
+		}
+
+		result, err := h.quarantineService.Reprocess(r.Context(), id, request.Payload)
+		if err != nil {
+			writeQuarantineError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, dto.ToReprocessResponse(result))
+	}
+}
+
+func (h *QuarantineHandler) HandleDiscard() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := uuid.Parse(mux.Vars(r)["id"])
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid rejected record id")
+			return
+		}
+
+		record, err := h.quarantineService.Discard(r.Context(), id)
+		if err != nil {
+			writeQuarantineError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, dto.ToRejectedRecordResponse(record))
+	}
+}
+
+func writeQuarantineError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, stock.ErrRejectedRecordNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, stock.ErrRecordAlreadyResolved):
+		writeError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrRecordStillInvalid):
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, "Error processing rejected record: "+err.Error())
+	}
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"stockapi/internal/domain/stock"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set(ContentType, ApplicationJSON)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+// clearWriteDeadline exempts a long running response, such as a sync or a
+// streamed export, from the server write timeout. Writers that cannot change
+// their deadline keep the timeout.
+func clearWriteDeadline(w http.ResponseWriter) {
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+}
+
+// symbolVar parses the symbol path variable as a ticker
+func symbolVar(r *http.Request) (stock.Ticker, error) {
+	symbol := mux.Vars(r)["symbol"]
+	ticker, err := stock.ParseTicker(symbol)
+	if err != nil {
+		return "", fmt.Errorf("invalid symbol: %q", symbol)
+	}
+	return ticker, nil
+}
+
+// tickerQuery parses an optional ticker query parameter, empty when absent
+func tickerQuery(r *http.Request, name string) (stock.Ticker, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return "", nil
+	}
+	ticker, err := stock.ParseTicker(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s parameter: %q", name, value)
+	}
+	return ticker, nil
+}
+
+// boolQuery parses an optional boolean query parameter, defaulting to false
+func boolQuery(r *http.Request, name string) (bool, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return false, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s parameter: %q", name, value)
+	}
+	return parsed, nil
+}
+
+// timeQuery parses an optional RFC 3339 timestamp or YYYY-MM-DD date query
+// parameter, returning the zero time when it is absent
+func timeQuery(r *http.Request, name string) (time.Time, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, nil
+	}
+	parsed, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s parameter: %q", name, value)
+	}
+	return parsed, nil
+}
+
+// durationQuery parses an optional Go duration query parameter such as "48h",
+// returning fallback when it is absent
+func durationQuery(r *http.Request, name string, fallback time.Duration) (time.Duration, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return fallback, nil
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed < 0 {
+		return 0, fmt.Errorf("invalid %s parameter: %q", name, value)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+	"stockapi/internal/application/dto"
+	"stockapi/internal/application/services"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+
+	// maxSearchQuery bounds the query length, in characters
+	maxSearchQuery = 100
+)
+
+type SearchHandler struct {
+	searchService *services.SearchService
+}
+
+func NewSearchHandler(service *services.SearchService) *SearchHandler {
+	return &SearchHandler{
+		searchService: service,
+	}
+}
+
+func (h *SearchHandler) HandleSearch() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := strings.TrimSpace(r.URL.Query().Get("q"))
+		if query == "" || utf8.RuneCountInString(query) > maxSearchQuery {
+			writeError(w, http.StatusBadRequest, "Invalid q parameter: must be 1 to "+strconv.Itoa(maxSearchQuery)+" characters")
+			return
+		}
+
+		limit := defaultSearchLimit
+		if value := r.URL.Query().Get("limit"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 1 || parsed > maxSearchLimit {
+				writeError(w, http.StatusBadRequest, "Invalid limit parameter: "+value)
+				return
+			}
+			limit = parsed
+		}
+
+		hits, err := h.searchService.Search(r.Context(), query, limit)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "Error searching stocks: "+err.Error())
+			return
+		}
+
+		writeJSON(w, http.StatusOK, dto.ToSearchResponse(query, hits))
+	}
+}
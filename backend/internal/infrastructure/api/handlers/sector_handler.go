@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"stockapi/internal/application/dto"
+	"stockapi/internal/application/services"
+	"stockapi/internal/domain/stock"
+
+	"github.com/gorilla/mux"
+)
+
+// MaxClassificationImport bounds the size of an uploaded classification CSV
+const MaxClassificationImport = 10 << 20
+
+type SectorHandler struct {
+	analysisService       *services.AnalysisApplicationService
+	classificationService *services.ClassificationService
+}
+
+func NewSectorHandler(analysisService *services.AnalysisApplicationService, classificationService *services.ClassificationService) *SectorHandler {
+	return &SectorHandler{
+		analysisService:       analysisService,
+		classificationService: classificationService,
+	}
+}
+
+func (h *SectorHandler) HandleSectors() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		since, err := timeQuery(r, "since")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		summaries, err := h.analysisService.SectorSummaries(r.Context(), since)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "Error summarizing sectors: "+err.Error())
+			return
+		}
+
+		writeJSON(w, http.StatusOK, dto.ToSectorSummariesResponse(summaries))
+	}
+}
+
+func (h *SectorHandler) HandleSectorDetail() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		since, err := timeQuery(r, "since")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		detail, err := h.analysisService.SectorDetail(r.Context(), mux.Vars(r)["sector"], since)
+		if errors.Is(err, stock.ErrSectorNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "Error summarizing sector: "+err.Error())
+			return
+		}
+
+		writeJSON(w, http.StatusOK, dto.ToSectorDetailResponse(detail))
+	}
+}
+
+// HandleImportClassifications replaces the classifications of the tickers in
+// the uploaded CSV
+func (h *SectorHandler) HandleImportClassifications() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body := http.MaxBytesReader(w, r.Body, MaxClassificationImport)
+
+		imported, err := h.classificationService.ImportCSV(r.Context(), body)
+		var importErr *services.ImportError
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &importErr):
+			writeJSON(w, http.StatusUnprocessableEntity, dto.ToImportErrorResponse(importErr))
+			return
+		case errors.As(err, &tooLarge):
+			writeError(w, http.StatusRequestEntityTooLarge, "Classification file is too large")
+			return
+		case err != nil:
+			writeError(w, http.StatusInternalServerError, "Error importing classifications: "+err.Error())
+			return
+		}
+
+		writeJSON(w, http.StatusOK, dto.ClassificationImportResponse{Imported: imported})
+	}
+}
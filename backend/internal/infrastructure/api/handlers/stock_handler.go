@@ -1,13 +1,13 @@
 package handlers
 
 import (
-	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
 	"stockapi/internal/application/dto"
 	"stockapi/internal/application/services"
 	"stockapi/internal/domain/stock"
-
-	"github.com/gorilla/mux"
+	"stockapi/internal/infrastructure/api/export"
 )
 
 const (
@@ -33,7 +33,7 @@ func (h *StockHandler) HandleStocks() http.HandlerFunc {
 		case http.MethodPost:
 			h.syncStocks(w, r)
 		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
 		}
 	}
 }
@@ -44,7 +44,7 @@ func (h *StockHandler) HandleStockDetail() http.HandlerFunc {
 		case http.MethodGet:
 			h.getStockDetail(w, r)
 		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
 		}
 	}
 }
@@ -52,13 +52,35 @@ func (h *StockHandler) HandleStockDetail() http.HandlerFunc {
 func (h *StockHandler) getStocks(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
+	format, err := export.Negotiate(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if format.IsStreamed() {
+		h.exportStocks(w, r, format)
+		return
+	}
+
+	// Read the version before the data so a concurrent write can only make
+	// the validator too old, never too new
+	version, err := h.stockService.DataVersion(ctx)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Error fetching stocks: "+err.Error())
+		return
+	}
+
 	var stocks []*stock.Stock
-	var err error
 
 	stocks, err = h.stockService.GetAllStocks(ctx)
 
 	if err != nil {
-		http.Error(w, "Error fetching stocks: "+err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, "Error fetching stocks: "+err.Error())
+		return
+	}
+
+	// Validators only go on successful responses
+	if setValidators(w, r, versionETag("stocks", version.Version), version.UpdatedAt) {
 		return
 	}
 
@@ -68,44 +90,121 @@ func (h *StockHandler) getStocks(w http.ResponseWriter, r *http.Request) {
 		stockResponses[i] = dto.ToStockResponse(s)
 	}
 
-	w.Header().Set(ContentType, ApplicationJSON)
-	json.NewEncoder(w).Encode(stockResponses)
+	writeJSON(w, http.StatusOK, stockResponses)
+}
+
+// exportStocks streams every stock from the repository cursor in the negotiated format
+func (h *StockHandler) exportStocks(w http.ResponseWriter, r *http.Request, format export.Format) {
+	clearWriteDeadline(w)
+	writer, err := export.NewWriter(w, format, "stocks", dto.StockExportColumns)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Error exporting stocks: "+err.Error())
+		return
+	}
+
+	err = h.stockService.StreamStocks(r.Context(), func(s *stock.Stock) error {
+		return writer.Write(dto.ToStockResponse(s))
+	})
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil && !writer.Started() {
+		writeError(w, http.StatusInternalServerError, "Error exporting stocks: "+err.Error())
+		return
+	}
+	if err != nil {
+		// Headers are already sent, so the truncated body is the only signal left
+		log.Printf("error exporting stocks as %s: %v", format, err)
+	}
 }
 
 func (h *StockHandler) syncStocks(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set(ContentType, ApplicationJSON)
+	// Syncs run for as long as the provider takes; shutdown cancels them
+	clearWriteDeadline(w)
+
+	var opts services.SyncOptions
+	var err error
+	if opts.Full, err = boolQuery(r, "full"); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	dryRun, err := boolQuery(r, "dry_run")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	ctx := r.Context()
-	if err := h.stockService.SyncStocksFromAPI(ctx); err != nil {
-		response := map[string]string{"error": "Error syncing stocks: " + err.Error()}
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(response)
+	if dryRun {
+		h.dryRunSync(w, r, opts)
+		return
+	}
+
+	run, err := h.stockService.SyncStocksFromAPI(ctx, opts)
+	var inProgress *stock.SyncInProgressError
+	if errors.As(err, &inProgress) {
+		writeJSON(w, http.StatusConflict, dto.ToSyncInProgressResponse(inProgress))
+		return
+	}
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrShuttingDown) {
+			status = http.StatusServiceUnavailable
+		}
+		writeError(w, status, "Error syncing stocks: "+err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, dto.SyncResultResponse{
+		Message: "Stocks synchronized successfully",
+		Run:     dto.ToSyncRunResponse(run),
+	})
+}
+
+func (h *StockHandler) dryRunSync(w http.ResponseWriter, r *http.Request, opts services.SyncOptions) {
+	report, err := h.stockService.DryRunSync(r.Context(), opts)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrShuttingDown) {
+			status = http.StatusServiceUnavailable
+		}
+		writeError(w, status, "Error running dry-run sync: "+err.Error())
 		return
 	}
 
-	response := map[string]string{"message": "Stocks synchronized successfully"}
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+	writeJSON(w, http.StatusOK, dto.ToDryRunResponse(report))
 }
 
 func (h *StockHandler) getStockDetail(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	symbol := vars["symbol"]
+	ticker, err := symbolVar(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	if symbol == "" {
-		http.Error(w, "Symbol is required", http.StatusBadRequest)
+	version, err := h.stockService.DataVersion(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Error fetching stock detail: "+err.Error())
 		return
 	}
 
-	stock, err := h.stockService.GetStockBySymbol(r.Context(), symbol)
+	stk, err := h.stockService.GetStockBySymbol(r.Context(), ticker)
+	if errors.Is(err, stock.ErrStockNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
 	if err != nil {
-		http.Error(w, "Error fetching stock detail: "+err.Error(), http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, "Error fetching stock detail: "+err.Error())
+		return
+	}
+
+	// A missing ticker must not match a cached validator
+	if setValidators(w, r, versionETag("stock", version.Version), version.UpdatedAt) {
 		return
 	}
 
 	// Convert entity to DTO
-	stockResponse := dto.ToStockResponse(stock)
+	stockResponse := dto.ToStockResponse(stk)
 
-	w.Header().Set(ContentType, ApplicationJSON)
-	json.NewEncoder(w).Encode(stockResponse)
+	writeJSON(w, http.StatusOK, stockResponse)
 }
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"stockapi/internal/application/dto"
+	"stockapi/internal/application/services"
+	"stockapi/internal/domain/stock"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type SyncHandler struct {
+	stockService *services.StockService
+}
+
+func NewSyncHandler(service *services.StockService) *SyncHandler {
+	return &SyncHandler{
+		stockService: service,
+	}
+}
+
+func (h *SyncHandler) HandleSyncChanges() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := uuid.Parse(mux.Vars(r)["id"])
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid sync run id")
+			return
+		}
+
+		run, changes, err := h.stockService.GetSyncRunChanges(r.Context(), id)
+		if errors.Is(err, stock.ErrSyncRunNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "Error fetching sync changes: "+err.Error())
+			return
+		}
+
+		writeJSON(w, http.StatusOK, dto.ToSyncChangesResponse(run, changes))
+	}
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+type adminKey struct{}
+
+// AdminAuth only lets through requests carrying one of the configured admin
+// bearer tokens, and stores the matching admin name in the request context.
+// With no tokens configured every admin request is refused.
+func AdminAuth(tokens map[string]string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || token == "" {
+				writeAuthError(w, http.StatusUnauthorized, "Missing admin bearer token")
+				return
+			}
+
+			name, ok := matchToken(tokens, token)
+			if !ok {
+				writeAuthError(w, http.StatusForbidden, "Invalid admin token")
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), adminKey{}, name)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// AdminFromContext returns the authenticated admin name set by AdminAuth
+func AdminFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(adminKey{}).(string)
+	return name
+}
+
+// matchToken compares against every token so the time taken does not reveal
+// which one was close
+func matchToken(tokens map[string]string, token string) (string, bool) {
+	var found string
+	for candidate, name := range tokens {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(token)) == 1 {
+			found = name
+		}
+	}
+	return found, found != ""
+}
+
+func writeAuthError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	if status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
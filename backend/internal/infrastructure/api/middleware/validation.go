@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gorilla/mux"
+)
+
+// ValidateRequest rejects requests whose parameters or body do not match the
+// OpenAPI document. Routes missing from the document are passed through.
+func ValidateRequest(doc *openapi3.T) (mux.MiddlewareFunc, error) {
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("error building openapi router: %w", err)
+	}
+
+	// CSV uploads are validated as plain strings; their handlers parse them
+	// and report errors by line
+	openapi3filter.RegisterBodyDecoder("text/csv", openapi3filter.FileBodyDecoder)
+
+	options := &openapi3filter.Options{
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+		MultiError:         true,
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route, pathParams, err := router.FindRoute(r)
+			if err != nil {
+				if errors.Is(err, routers.ErrPathNotFound) || errors.Is(err, routers.ErrMethodNotAllowed) {
+					next.ServeHTTP(w, r)
+					return
+				}
+				writeValidationError(w, err)
+				return
+			}
+
+			input := &openapi3filter.RequestValidationInput{
+				Request:    r,
+				PathParams: pathParams,
+				Route:      route,
+				Options:    options,
+			}
+			if err := openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+				// A body cut off by LimitBody is too large rather than invalid
+				if errors.As(err, new(*http.MaxBytesError)) {
+					writeTooLarge(w)
+					return
+				}
+				writeValidationError(w, err)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+func writeValidationError(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request: " + err.Error()})
+}
@@ -0,0 +1,51 @@
+package openapi
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+//go:embed openapi.json
+var specJSON []byte
+
+//go:embed docs.html
+var docsHTML []byte
+
+//go:embed swagger-ui/*.js swagger-ui/*.css swagger-ui/*.png
+var docsAssets embed.FS
+
+// Load parses and validates the embedded OpenAPI document
+func Load(ctx context.Context) (*openapi3.T, error) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(specJSON)
+	if err != nil {
+		return nil, fmt.Errorf("error loading openapi spec: %w", err)
+	}
+	if err := doc.Validate(ctx); err != nil {
+		return nil, fmt.Errorf("invalid openapi spec: %w", err)
+	}
+	return doc, nil
+}
+
+// SpecJSON returns the raw embedded OpenAPI document
+func SpecJSON() []byte {
+	return specJSON
+}
+
+// DocsHTML returns the documentation page that renders the spec
+func DocsHTML() []byte {
+	return docsHTML
+}
+
+// DocsAssets returns the bundled Swagger UI files the documentation page loads
+func DocsAssets() fs.FS {
+	assets, err := fs.Sub(docsAssets, "swagger-ui")
+	if err != nil {
+		panic(err)
+	}
+	return assets
+}
@@ -0,0 +1,6 @@
+window.onload = () => {
+  window.ui = SwaggerUIBundle({
+    url: "/api/openapi.json",
+    dom_id: "#swagger-ui",
+  });
+};
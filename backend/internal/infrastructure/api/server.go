@@ -1,60 +1,206 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"stockapi/internal/application"
 	"stockapi/internal/infrastructure/api/handlers"
 	"stockapi/internal/infrastructure/api/middleware"
+	"stockapi/internal/infrastructure/api/openapi"
 	"stockapi/internal/infrastructure/config"
 
 	"github.com/gorilla/mux"
 )
 
 type Server struct {
-	config          *config.Config
-	app             *application.StockApplication
-	stockHandler    *handlers.StockHandler
-	analysisHandler *handlers.AnalysisHandler
-	router          *mux.Router
+	config            *config.Config
+	app               *application.StockApplication
+	stockHandler      *handlers.StockHandler
+	analysisHandler   *handlers.AnalysisHandler
+	healthHandler     *handlers.HealthHandler
+	docsHandler       *handlers.DocsHandler
+	syncHandler       *handlers.SyncHandler
+	quarantineHandler *handlers.QuarantineHandler
+	sectorHandler     *handlers.SectorHandler
+	portfolioHandler  *handlers.PortfolioHandler
+	anomalyHandler    *handlers.AnomalyHandler
+	compareHandler    *handlers.CompareHandler
+	searchHandler     *handlers.SearchHandler
+	correctionHandler *handlers.CorrectionHandler
+	router            *mux.Router
+	httpServer        *http.Server
 }
 
-func NewServer(cfg *config.Config, app *application.StockApplication) *Server {
+func NewServer(cfg *config.Config, app *application.StockApplication) (*Server, error) {
 	server := &Server{
-		config: cfg,
-		app:    app,
-		router: mux.NewRouter(),
+		config:      cfg,
+		app:         app,
+		router:      mux.NewRouter(),
+		docsHandler: handlers.NewDocsHandler(),
 	}
 
 	if app != nil {
 		server.stockHandler = handlers.NewStockHandler(app.StockService)
 		server.analysisHandler = handlers.NewAnalysisHandler(app.AnalysisService)
+		server.healthHandler = handlers.NewHealthHandler(app.HealthService)
+		server.syncHandler = handlers.NewSyncHandler(app.StockService)
+		server.quarantineHandler = handlers.NewQuarantineHandler(app.QuarantineService)
+		server.sectorHandler = handlers.NewSectorHandler(app.AnalysisService, app.ClassificationService)
+		server.portfolioHandler = handlers.NewPortfolioHandler(app.PortfolioService)
+		server.anomalyHandler = handlers.NewAnomalyHandler(app.AnomalyService)
+		server.compareHandler = handlers.NewCompareHandler(app.AnalysisService, app.ComparisonService)
+		server.searchHandler = handlers.NewSearchHandler(app.SearchService)
+		server.correctionHandler = handlers.NewCorrectionHandler(app.CorrectionService)
 	}
 
-	server.setupRoutes()
-	return server
+	spec, err := openapi.Load(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	validator, err := middleware.ValidateRequest(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	server.setupRoutes(validator)
+	server.httpServer = &http.Server{
+		Addr:         fmt.Sprintf(":%s", cfg.Port),
+		Handler:      server.router,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
+	}
+	if app != nil {
+		// Cancel running syncs as soon as shutdown starts so their requests can drain
+		server.httpServer.RegisterOnShutdown(app.StockService.CancelSyncs)
+	}
+	return server, nil
 }
 
-func (s *Server) setupRoutes() {
+func (s *Server) setupRoutes(validator mux.MiddlewareFunc) {
+	// Probe routes stay outside the API middleware so they are never rate limited
+	s.router.HandleFunc("/healthz", s.healthHandler.HandleLiveness()).
+		Methods(http.MethodGet)
+
+	s.router.HandleFunc("/readyz", s.healthHandler.HandleReadiness()).
+		Methods(http.MethodGet)
+
 	// API routes
-	s.router.HandleFunc("/api/stocks", s.stockHandler.HandleStocks()).
+	api := s.router.PathPrefix("/api").Subrouter()
+
+	api.HandleFunc("/openapi.json", s.docsHandler.HandleSpec()).
+		Methods(http.MethodGet)
+
+	api.HandleFunc("/docs", s.docsHandler.HandleDocs()).
+		Methods(http.MethodGet)
+
+	api.PathPrefix("/docs/").Handler(s.docsHandler.HandleAssets()).
+		Methods(http.MethodGet)
+
+	api.HandleFunc("/stocks", s.stockHandler.HandleStocks()).
 		Methods(http.MethodGet, http.MethodPost, http.MethodOptions)
 
-	s.router.HandleFunc("/api/stocks/recommended", s.analysisHandler.HandleAnalysis()).
+	api.HandleFunc("/stocks/recommended", s.analysisHandler.HandleAnalysis()).
+		Methods(http.MethodGet, http.MethodOptions)
+
+	api.HandleFunc("/stocks/{symbol}", s.stockHandler.HandleStockDetail()).
+		Methods(http.MethodGet, http.MethodOptions)
+
+	api.HandleFunc("/stocks/{symbol}/scores", s.analysisHandler.HandleScoreHistory()).
 		Methods(http.MethodGet, http.MethodOptions)
 
-	s.router.HandleFunc("/api/stocks/{symbol}", s.stockHandler.HandleStockDetail()).
+	api.HandleFunc("/stocks/{symbol}/explain", s.analysisHandler.HandleExplain()).
 		Methods(http.MethodGet, http.MethodOptions)
 
-	// Apply global middleware
-	s.router.Use(middleware.Logging)
-	s.router.Use(middleware.CORS(s.config))
-	s.router.Use(middleware.RateLimit)
+	api.HandleFunc("/analysis/simulate", s.analysisHandler.HandleSimulate()).
+		Methods(http.MethodPost, http.MethodOptions)
+
+	api.HandleFunc("/sectors", s.sectorHandler.HandleSectors()).
+		Methods(http.MethodGet, http.MethodOptions)
+
+	api.HandleFunc("/sectors/{sector}", s.sectorHandler.HandleSectorDetail()).
+		Methods(http.MethodGet, http.MethodOptions)
+
+	api.HandleFunc("/portfolios", s.portfolioHandler.HandlePortfolios()).
+		Methods(http.MethodGet, http.MethodOptions)
+
+	api.HandleFunc("/portfolios/construct", s.portfolioHandler.HandleConstruct()).
+		Methods(http.MethodPost, http.MethodOptions)
+
+	api.HandleFunc("/portfolios/holdings", s.portfolioHandler.HandleUploadHoldings()).
+		Methods(http.MethodPost, http.MethodOptions)
+
+	api.HandleFunc("/portfolios/{id}", s.portfolioHandler.HandlePortfolio()).
+		Methods(http.MethodGet, http.MethodOptions)
+
+	api.HandleFunc("/portfolios/{id}/review", s.portfolioHandler.HandleReview()).
+		Methods(http.MethodGet, http.MethodOptions)
+
+	api.HandleFunc("/anomalies", s.anomalyHandler.HandleAnomalies()).
+		Methods(http.MethodGet, http.MethodOptions)
+
+	api.HandleFunc("/compare", s.compareHandler.HandleCompare()).
+		Methods(http.MethodGet, http.MethodOptions)
+
+	api.HandleFunc("/search", s.searchHandler.HandleSearch()).
+		Methods(http.MethodGet, http.MethodOptions)
+
+	api.HandleFunc("/sync/{id}/changes", s.syncHandler.HandleSyncChanges()).
+		Methods(http.MethodGet, http.MethodOptions)
+
+	// Manual corrections of stored records share the stock path but require
+	// an admin token
+	records := api.Methods(http.MethodPut, http.MethodPatch, http.MethodDelete).Subrouter()
+
+	records.HandleFunc("/stocks/{id}", s.correctionHandler.HandleStockRecord())
+
+	// Admin routes additionally require an admin token
+	admin := api.PathPrefix("/admin").Subrouter()
+
+	admin.HandleFunc("/rejected-records", s.quarantineHandler.HandleRejectedRecords()).
+		Methods(http.MethodGet, http.MethodOptions)
+
+	admin.HandleFunc("/rejected-records/{id}/reprocess", s.quarantineHandler.HandleReprocess()).
+		Methods(http.MethodPost, http.MethodOptions)
+
+	admin.HandleFunc("/rejected-records/{id}/discard", s.quarantineHandler.HandleDiscard()).
+		Methods(http.MethodPost, http.MethodOptions)
+
+	admin.HandleFunc("/classifications", s.sectorHandler.HandleImportClassifications()).
+		Methods(http.MethodPost, http.MethodOptions)
+
+	admin.HandleFunc("/stocks/{id}/release", s.correctionHandler.HandleRelease()).
+		Methods(http.MethodPost, http.MethodOptions)
+
+	admin.HandleFunc("/stocks/{id}/corrections", s.correctionHandler.HandleCorrections()).
+		Methods(http.MethodGet, http.MethodOptions)
+
+	// Apply API middleware
+	api.Use(middleware.Logging)
+	api.Use(middleware.CORS(s.config))
+	api.Use(middleware.RateLimit)
+	api.Use(middleware.LimitBody(map[string]int64{
+		"/api/admin/classifications": handlers.MaxClassificationImport,
+		"/api/portfolios/holdings":   handlers.MaxHoldingsUpload,
+	}))
+	api.Use(validator)
+	admin.Use(middleware.AdminAuth(s.config.AdminTokens))
+	records.Use(middleware.AdminAuth(s.config.AdminTokens))
 }
 
+// Run blocks serving requests until the server fails or Shutdown is called
 func (s *Server) Run() error {
-	addr := fmt.Sprintf(":%s", s.config.Port)
 	log.Printf("Server starting on port %s", s.config.Port)
-	return http.ListenAndServe(addr, s.router)
+	if err := s.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops accepting connections and waits for in-flight requests to finish
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.httpServer.Shutdown(ctx)
 }
@@ -1,29 +1,218 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+const (
+	ProviderHTTP = "http"
+	ProviderFile = "file"
+)
+
+// ProviderConfig describes one upstream StockAPIPort: "http" for a live API
+// or "file" to replay captured pages from Path
+type ProviderConfig struct {
+	Name      string
+	Type      string
+	URL       string
+	AuthToken string
+	Path      string
+}
+
 type Config struct {
 	Port           string
 	DatabaseURL    string
 	ExternalAPIURL string
 	AuthToken      string
 	AllowedOrigin  string
+
+	// Providers lists the upstream stock providers, highest priority first
+	Providers []ProviderConfig
+
+	// AdminTokens maps each admin bearer token to the admin it identifies
+	AdminTokens map[string]string
+
+	// FullSyncInterval forces a full provider crawl when the last one is older
+	FullSyncInterval time.Duration
+
+	// SyncLeaseTTL is how long the sync lease outlives its last heartbeat
+	SyncLeaseTTL time.Duration
+
+	// InstanceID identifies this replica as the holder of the sync lease
+	InstanceID string
+
+	// AnalysisCacheTTL bounds how long computed analyses are reused
+	AnalysisCacheTTL time.Duration
+
+	// AnalysisSnapshots persists computed analyses for other replicas
+	AnalysisSnapshots bool
+
+	// ScoreHistoryRetention is how long recorded scores are kept
+	ScoreHistoryRetention time.Duration
+
+	// ScoreHistoryInterval is how often the default scores are recorded
+	ScoreHistoryInterval time.Duration
+
+	// StalenessWindow is how long after its rating action a stock is analyzed
+	StalenessWindow time.Duration
+
+	// RatingHalfLife is how long a rating takes to lose half its score weight
+	RatingHalfLife time.Duration
+
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+	IdleTimeout     time.Duration
+	ShutdownTimeout time.Duration
 }
 
 func Load() (*Config, error) {
 	godotenv.Load() // Load .env variables if exists
 
-	return &Config{
+	cfg := &Config{
 		Port:           getEnvOrDefault("PORT", "8080"),
 		DatabaseURL:    os.Getenv("DATABASE_URL"),
 		ExternalAPIURL: os.Getenv("EXTERNAL_API_URL"),
 		AuthToken:      os.Getenv("AUTH_TOKEN"),
 		AllowedOrigin:  getEnvOrDefault("ALLOWED_ORIGIN", "*"),
-	}, nil
+	}
+
+	var err error
+	if cfg.Providers, err = loadProviders(cfg); err != nil {
+		return nil, err
+	}
+	if cfg.AdminTokens, err = loadAdminTokens(); err != nil {
+		return nil, err
+	}
+	if cfg.FullSyncInterval, err = getDurationOrDefault("FULL_SYNC_INTERVAL", 24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.SyncLeaseTTL, err = getDurationOrDefault("SYNC_LEASE_TTL", 30*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.SyncLeaseTTL < time.Second {
+		return nil, fmt.Errorf("SYNC_LEASE_TTL must be at least 1s, got %s", cfg.SyncLeaseTTL)
+	}
+	if cfg.InstanceID = os.Getenv("INSTANCE_ID"); cfg.InstanceID == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("error reading hostname for INSTANCE_ID: %w", err)
+		}
+		cfg.InstanceID = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+	if cfg.AnalysisCacheTTL, err = getDurationOrDefault("ANALYSIS_CACHE_TTL", 10*time.Minute); err != nil {
+		return nil, err
+	}
+	if cfg.AnalysisSnapshots, err = getBoolOrDefault("ANALYSIS_SNAPSHOTS", false); err != nil {
+		return nil, err
+	}
+	if cfg.ScoreHistoryRetention, err = getDurationOrDefault("SCORE_HISTORY_RETENTION", 365*24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.ScoreHistoryInterval, err = getDurationOrDefault("SCORE_HISTORY_INTERVAL", time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.StalenessWindow, err = getDurationOrDefault("ANALYSIS_STALENESS_WINDOW", 24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.RatingHalfLife, err = getDurationOrDefault("RATING_HALF_LIFE", 7*24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.ReadTimeout, err = getDurationOrDefault("HTTP_READ_TIMEOUT", 10*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.WriteTimeout, err = getDurationOrDefault("HTTP_WRITE_TIMEOUT", 60*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.IdleTimeout, err = getDurationOrDefault("HTTP_IDLE_TIMEOUT", 120*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.ShutdownTimeout, err = getDurationOrDefault("SHUTDOWN_TIMEOUT", 15*time.Second); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// loadProviders reads STOCK_PROVIDERS, a comma separated list of provider
+// names in priority order. Each provider is configured through
+// STOCK_PROVIDER_<NAME>_TYPE, _URL, _TOKEN and _PATH. Without the list, a
+// single "primary" provider is built from STOCK_PROVIDER, EXTERNAL_API_URL,
+// AUTH_TOKEN and STOCK_PROVIDER_PATH.
+func loadProviders(cfg *Config) ([]ProviderConfig, error) {
+	var providers []ProviderConfig
+
+	names := os.Getenv("STOCK_PROVIDERS")
+	if names == "" {
+		providers = append(providers, ProviderConfig{
+			Name:      "primary",
+			Type:      getEnvOrDefault("STOCK_PROVIDER", ProviderHTTP),
+			URL:       cfg.ExternalAPIURL,
+			AuthToken: cfg.AuthToken,
+			Path:      os.Getenv("STOCK_PROVIDER_PATH"),
+		})
+	}
+
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("provider %q is listed twice in STOCK_PROVIDERS", name)
+		}
+		seen[name] = true
+
+		prefix := "STOCK_PROVIDER_" + strings.ToUpper(name) + "_"
+		providers = append(providers, ProviderConfig{
+			Name:      name,
+			Type:      getEnvOrDefault(prefix+"TYPE", ProviderHTTP),
+			URL:       os.Getenv(prefix + "URL"),
+			AuthToken: os.Getenv(prefix + "TOKEN"),
+			Path:      os.Getenv(prefix + "PATH"),
+		})
+	}
+
+	for _, provider := range providers {
+		switch provider.Type {
+		case ProviderHTTP:
+		case ProviderFile:
+			if provider.Path == "" {
+				return nil, fmt.Errorf("provider %q of type %q requires a path", provider.Name, ProviderFile)
+			}
+		default:
+			return nil, fmt.Errorf("provider %q has unknown type %q", provider.Name, provider.Type)
+		}
+	}
+	return providers, nil
+}
+
+// loadAdminTokens reads ADMIN_TOKENS, a comma separated list of name:token
+// pairs. Admin endpoints refuse every request when it is empty.
+func loadAdminTokens() (map[string]string, error) {
+	tokens := make(map[string]string)
+	for _, pair := range strings.Split(os.Getenv("ADMIN_TOKENS"), ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, token, ok := strings.Cut(pair, ":")
+		name, token = strings.TrimSpace(name), strings.TrimSpace(token)
+		if !ok || name == "" || token == "" {
+			return nil, fmt.Errorf("invalid ADMIN_TOKENS entry %q, expected name:token", pair)
+		}
+		if _, dup := tokens[token]; dup {
+			return nil, fmt.Errorf("admin token for %q is already assigned", name)
+		}
+		tokens[token] = name
+	}
+	return tokens, nil
 }
 
 func getEnvOrDefault(key, defaultValue string) string {
@@ -32,3 +221,27 @@ func getEnvOrDefault(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getDurationOrDefault(key string, defaultValue time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration for %s: %w", key, err)
+	}
+	return d, nil
+}
+
+func getBoolOrDefault(key string, defaultValue bool) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid boolean for %s: %w", key, err)
+	}
+	return b, nil
+}
@@ -0,0 +1,95 @@
+package stockapi
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"
+	CircuitOpen     CircuitState = "open"
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+var ErrCircuitOpen = errors.New("external API circuit is open")
+
+// circuitBreaker stops calling the provider after too many consecutive
+// failures and lets a single trial request through once the cooldown expires
+type circuitBreaker struct {
+	mu        sync.Mutex
+	state     CircuitState
+	failures  int
+	threshold int
+	cooldown  time.Duration
+	openedAt  time.Time
+	// probing is set while the half-open trial request is in flight
+	probing bool
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		state:     CircuitClosed,
+		threshold: threshold,
+		cooldown:  cooldown,
+	}
+}
+
+// allow reports whether a request may be sent. Once the cooldown expires only
+// the first caller gets through; the others are rejected until the trial
+// request is recorded as a success or a failure.
+func (cb *circuitBreaker) allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case CircuitOpen:
+		if time.Since(cb.openedAt) < cb.cooldown {
+			return false
+		}
+		cb.state = CircuitHalfOpen
+		cb.probing = true
+		return true
+	case CircuitHalfOpen:
+		if cb.probing {
+			return false
+		}
+		cb.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (cb *circuitBreaker) recordSuccess() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.failures = 0
+	cb.state = CircuitClosed
+	cb.probing = false
+}
+
+func (cb *circuitBreaker) recordFailure() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.failures++
+	cb.probing = false
+	if cb.state == CircuitHalfOpen || cb.failures >= cb.threshold {
+		cb.state = CircuitOpen
+		cb.openedAt = time.Now()
+	}
+}
+
+func (cb *circuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == CircuitOpen && time.Since(cb.openedAt) >= cb.cooldown {
+		return CircuitHalfOpen
+	}
+	return cb.state
+}
@@ -17,6 +17,7 @@ type StockAPIClient struct {
 	httpClient *http.Client
 	authToken  string
 	logger     shared.Logger
+	breaker    *circuitBreaker
 }
 
 type apiResponse struct {
@@ -42,101 +43,181 @@ func NewStockAPIClient(baseURL, authToken string, logger shared.Logger) stock.St
 		authToken:  authToken,
 		httpClient: &http.Client{Timeout: 10 * time.Second},
 		logger:     logger,
+		breaker:    newCircuitBreaker(5, 30*time.Second),
 	}
 }
 
-func (c *StockAPIClient) FetchStocks(ctx context.Context) ([]*stock.Stock, error) {
-	var allStocks []*stock.Stock
+// FetchStocks crawls the provider page by page. With a high-water mark in
+// opts it assumes newest-first pages and stops after the first page that
+// reaches events older than the mark.
+func (c *StockAPIClient) FetchStocks(ctx context.Context, opts stock.FetchOptions) (*stock.FetchResult, error) {
+	result := &stock.FetchResult{}
 	nextPage := "" // Initially empty
 
 	for hasMorePages := true; hasMorePages; {
-		// Build the URL
-		url := c.baseURL + "/list"
-		if nextPage != "" {
-			url = fmt.Sprintf("%s/list?next_page=%s", c.baseURL, nextPage)
+		apiResp, err := c.fetchPage(ctx, nextPage)
+		if err != nil {
+			return nil, err
 		}
 
-		c.logger.Debug(ctx, "Starting request to the API", map[string]interface{}{
-			"url": url,
+		stocks, rejected := convertToStocks(apiResp.Items)
+		stocks, reachedMark := keepSince(stocks, opts.Since)
+		result.Stocks = append(result.Stocks, stocks...)
+		result.Rejected = append(result.Rejected, rejected...)
+
+		c.logger.Info(ctx, "Successfully obtained stocks page", map[string]interface{}{
+			"count_in_page":    len(stocks),
+			"rejected_in_page": len(rejected),
+			"total_so_far":     len(result.Stocks),
+			"next_page":        apiResp.NextPage,
 		})
 
-		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-		if err != nil {
-			c.logger.Error(ctx, "Error creating request", map[string]interface{}{
-				"error": err.Error(),
+		if reachedMark {
+			c.logger.Info(ctx, "Reached already synchronized events", map[string]interface{}{
+				"since": opts.Since,
 			})
-			return nil, fmt.Errorf("error creating request: %w", err)
 		}
 
-		req.Header.Set("Authorization", "Bearer "+c.authToken)
-		req.Header.Set("Content-Type", "application/json")
+		// Update the loop condition
+		hasMorePages = apiResp.NextPage != "" && !reachedMark
+		nextPage = apiResp.NextPage
+	}
 
-		resp, err := c.httpClient.Do(req)
-		if err != nil {
-			return nil, fmt.Errorf("error fetching stocks: %w", err)
-		}
-		defer resp.Body.Close()
+	return result, nil
+}
 
-		var apiResp apiResponse
-		if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-			return nil, fmt.Errorf("error decoding response: %w", err)
-		}
+// CircuitState reports whether calls to the provider are currently allowed
+func (c *StockAPIClient) CircuitState() string {
+	return string(c.breaker.State())
+}
 
-		stocks, err := c.convertToStocks(apiResp.Items)
-		if err != nil {
-			return nil, err
-		}
+func (c *StockAPIClient) fetchPage(ctx context.Context, nextPage string) (*apiResponse, error) {
+	// Build the URL
+	url := c.baseURL + "/list"
+	if nextPage != "" {
+		url = fmt.Sprintf("%s/list?next_page=%s", c.baseURL, nextPage)
+	}
 
-		allStocks = append(allStocks, stocks...)
+	c.logger.Debug(ctx, "Starting request to the API", map[string]interface{}{
+		"url": url,
+	})
 
-		c.logger.Info(ctx, "Successfully obtained stocks page", map[string]interface{}{
-			"count_in_page": len(stocks),
-			"total_so_far":  len(allStocks),
-			"next_page":     apiResp.NextPage,
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		c.logger.Error(ctx, "Error creating request", map[string]interface{}{
+			"error": err.Error(),
 		})
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
 
-		// Update the loop condition
-		hasMorePages = apiResp.NextPage != ""
-		nextPage = apiResp.NextPage
+	req.Header.Set("Authorization", "Bearer "+c.authToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	// Every request let through must be recorded below, or a half-open
+	// breaker would wait forever for its trial request
+	if !c.breaker.allow() {
+		return nil, ErrCircuitOpen
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		c.breaker.recordFailure()
+		return nil, fmt.Errorf("error fetching stocks: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		c.breaker.recordFailure()
+		return nil, fmt.Errorf("error fetching stocks: unexpected status %d", resp.StatusCode)
+	}
+
+	var apiResp apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		c.breaker.recordFailure()
+		return nil, fmt.Errorf("error decoding response: %w", err)
 	}
 
-	return allStocks, nil
+	c.breaker.recordSuccess()
+	return &apiResp, nil
 }
 
-func (c *StockAPIClient) convertToStocks(items []stockDTO) ([]*stock.Stock, error) {
+// convertToStocks validates every record on its own, so a malformed record is
+// rejected without losing the rest of the page
+func convertToStocks(items []stockDTO) ([]*stock.Stock, []stock.RejectedRecord) {
 	var stocks []*stock.Stock
+	var rejected []stock.RejectedRecord
 	for _, item := range items {
-		targetFrom, err := parseMoneyString(item.TargetFrom)
+		stockEntity, err := convertToStock(item)
 		if err != nil {
-			return nil, fmt.Errorf("error parsing target from: %w", err)
+			payload, _ := json.Marshal(item)
+			rejected = append(rejected, stock.RejectedRecord{
+				Payload: payload,
+				Reason:  err.Error(),
+			})
+			continue
 		}
+		stocks = append(stocks, stockEntity)
+	}
+	return stocks, rejected
+}
 
-		targetTo, err := parseMoneyString(item.TargetTo)
-		if err != nil {
-			return nil, fmt.Errorf("error parsing target to: %w", err)
-		}
+func convertToStock(item stockDTO) (*stock.Stock, error) {
+	if err := validateRecord(item); err != nil {
+		return nil, err
+	}
 
-		stockEntity, err := stock.NewStock(item.Ticker, targetFrom, targetTo)
-		if err != nil {
-			return nil, fmt.Errorf("error creating stock entity: %w", err)
-		}
+	targetFrom, err := parseMoneyString(item.TargetFrom)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing target from: %w", err)
+	}
 
-		stockEntity.Company = item.Company
-		stockEntity.Action = item.Action
-		stockEntity.Brokerage = item.Brokerage
-		stockEntity.Rating.From = stock.Rating(item.RatingFrom)
-		stockEntity.Rating.To = stock.Rating(item.RatingTo)
+	targetTo, err := parseMoneyString(item.TargetTo)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing target to: %w", err)
+	}
 
-		// Parse time
-		t, err := time.Parse(time.RFC3339, item.Time)
-		if err != nil {
-			return nil, fmt.Errorf("error parsing time: %w", err)
-		}
-		stockEntity.Time = t
+	if targetFrom.Amount < 0 || targetTo.Amount < 0 {
+		return nil, errNegativeTarget
+	}
 
-		stocks = append(stocks, stockEntity)
+	stockEntity, err := stock.NewStock(item.Ticker, targetFrom, targetTo)
+	if err != nil {
+		return nil, fmt.Errorf("error creating stock entity: %w", err)
+	}
+
+	stockEntity.Company = item.Company
+	stockEntity.Action = item.Action
+	stockEntity.Brokerage = item.Brokerage
+	stockEntity.Rating.From = stock.Rating(item.RatingFrom)
+	stockEntity.Rating.To = stock.Rating(item.RatingTo)
+
+	// Parse time
+	t, err := time.Parse(time.RFC3339, item.Time)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing time: %w", err)
+	}
+	stockEntity.Time = t
+
+	return stockEntity, nil
+}
+
+// keepSince drops stocks older than the high-water mark and reports whether
+// any were found, meaning the crawl reached already synchronized events
+func keepSince(stocks []*stock.Stock, since time.Time) ([]*stock.Stock, bool) {
+	if since.IsZero() {
+		return stocks, false
+	}
+
+	kept := stocks[:0]
+	reached := false
+	for _, s := range stocks {
+		if s.Time.Before(since) {
+			reached = true
+			continue
+		}
+		kept = append(kept, s)
 	}
-	return stocks, nil
+	return kept, reached
 }
 
 func parseMoneyString(value string) (stock.Money, error) {
@@ -0,0 +1,151 @@
+package stockapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"stockapi/internal/domain/shared"
+	"stockapi/internal/domain/stock"
+	"strings"
+	"sync"
+)
+
+// NamedProvider is an upstream provider and the source name stored on its records
+type NamedProvider struct {
+	Name string
+	Port stock.StockAPIPort
+}
+
+// CompositeStockProvider fans out to several providers concurrently and merges
+// their results. The same action reported by more than one provider is kept
+// once, taking the record of the provider listed first.
+type CompositeStockProvider struct {
+	providers []NamedProvider
+	logger    shared.Logger
+}
+
+func NewCompositeStockProvider(providers []NamedProvider, logger shared.Logger) stock.StockAPIPort {
+	return &CompositeStockProvider{
+		providers: providers,
+		logger:    logger,
+	}
+}
+
+type providerResult struct {
+	result *stock.FetchResult
+	err    error
+}
+
+// FetchStocks queries every provider with its own high-water mark, looked up
+// in opts by provider name
+func (p *CompositeStockProvider) FetchStocks(ctx context.Context, opts stock.FetchOptions) (*stock.FetchResult, error) {
+	results := make([]providerResult, len(p.providers))
+
+	var wg sync.WaitGroup
+	for i, provider := range p.providers {
+		wg.Add(1)
+		go func(i int, provider NamedProvider) {
+			defer wg.Done()
+			result, err := provider.Port.FetchStocks(ctx, stock.FetchOptions{
+				Since: opts.SinceFor(provider.Name),
+			})
+			results[i] = providerResult{result: result, err: err}
+		}(i, provider)
+	}
+	wg.Wait()
+
+	// A failing provider only loses its own records unless every provider failed
+	var errs []error
+	for i, result := range results {
+		if result.err == nil {
+			continue
+		}
+		name := p.providers[i].Name
+		p.logger.Warn(ctx, "Stock provider failed", map[string]interface{}{
+			"source": name,
+			"error":  result.err.Error(),
+		})
+		errs = append(errs, fmt.Errorf("provider %s: %w", name, result.err))
+	}
+	if len(errs) == len(p.providers) {
+		return nil, errors.Join(errs...)
+	}
+
+	return p.merge(ctx, results), nil
+}
+
+// merge walks the results in priority order so the first provider to report
+// an action wins any conflict with lower priority providers. Rejected records
+// are kept from every provider.
+func (p *CompositeStockProvider) merge(ctx context.Context, results []providerResult) *stock.FetchResult {
+	seen := make(map[string]*stock.Stock)
+	merged := &stock.FetchResult{}
+	duplicates := 0
+
+	for i, result := range results {
+		if result.err != nil {
+			continue
+		}
+
+		for _, rejected := range result.result.Rejected {
+			rejected.Source = p.providers[i].Name
+			merged.Rejected = append(merged.Rejected, rejected)
+		}
+
+		for _, stk := range result.result.Stocks {
+			stk.Source = p.providers[i].Name
+
+			key := actionKey(stk)
+			kept, exists := seen[key]
+			if !exists {
+				seen[key] = stk
+				merged.Stocks = append(merged.Stocks, stk)
+				continue
+			}
+
+			duplicates++
+			if kept.Rating.To != stk.Rating.To || kept.Target.To.Amount != stk.Target.To.Amount {
+				p.logger.Debug(ctx, "Conflicting action resolved by provider priority", map[string]interface{}{
+					"ticker":      stk.Ticker,
+					"brokerage":   stk.Brokerage,
+					"kept_source": kept.Source,
+					"drop_source": stk.Source,
+				})
+			}
+		}
+	}
+
+	p.logger.Info(ctx, "Merged stocks from providers", map[string]interface{}{
+		"providers":  len(p.providers),
+		"merged":     len(merged.Stocks),
+		"rejected":   len(merged.Rejected),
+		"duplicates": duplicates,
+	})
+	return merged
+}
+
+// CircuitState reports the worst circuit state among the providers that have one
+func (p *CompositeStockProvider) CircuitState() string {
+	state := CircuitClosed
+	for _, provider := range p.providers {
+		reporter, ok := provider.Port.(interface{ CircuitState() string })
+		if !ok {
+			continue
+		}
+		switch CircuitState(reporter.CircuitState()) {
+		case CircuitOpen:
+			return string(CircuitOpen)
+		case CircuitHalfOpen:
+			state = CircuitHalfOpen
+		}
+	}
+	return string(state)
+}
+
+// actionKey identifies a rating action independently of the provider that
+// reported it: the same broker acting on the same ticker on the same day
+func actionKey(s *stock.Stock) string {
+	return s.Ticker.String() + "|" +
+		strings.ToLower(strings.TrimSpace(s.Brokerage)) + "|" +
+		s.Time.UTC().Format("2006-01-02")
+}
@@ -0,0 +1,185 @@
+package stockapi
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"stockapi/internal/domain/shared"
+	"stockapi/internal/domain/stock"
+	"strings"
+)
+
+// FileStockProvider replays captured provider data from disk. The source is a
+// directory or a .zip archive holding JSON pages in the same {items, next_page}
+// shape the API returns, or CSV files whose header uses the same field names.
+// Files are read in lexical order, so captures should be named page-0001.json,
+// page-0002.json, and so on.
+type FileStockProvider struct {
+	source string
+	logger shared.Logger
+}
+
+func NewFileStockProvider(source string, logger shared.Logger) (stock.StockAPIPort, error) {
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, fmt.Errorf("error opening stock provider source: %w", err)
+	}
+	if !info.IsDir() && !strings.HasSuffix(strings.ToLower(source), ".zip") {
+		return nil, fmt.Errorf("stock provider source %s must be a directory or a .zip archive", source)
+	}
+
+	return &FileStockProvider{
+		source: source,
+		logger: logger,
+	}, nil
+}
+
+// FetchStocks reads every page in the source, keeping only events at or after
+// the high-water mark in opts
+func (p *FileStockProvider) FetchStocks(ctx context.Context, opts stock.FetchOptions) (*stock.FetchResult, error) {
+	fsys, closeFS, err := p.open()
+	if err != nil {
+		return nil, err
+	}
+	defer closeFS()
+
+	files, err := pageFiles(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &stock.FetchResult{}
+	for _, name := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		items, err := readPage(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+
+		stocks, rejected := convertToStocks(items)
+		stocks, _ = keepSince(stocks, opts.Since)
+		result.Stocks = append(result.Stocks, stocks...)
+		result.Rejected = append(result.Rejected, rejected...)
+
+		p.logger.Info(ctx, "Successfully read stocks page from file", map[string]interface{}{
+			"file":             name,
+			"count_in_page":    len(stocks),
+			"rejected_in_page": len(rejected),
+			"total_so_far":     len(result.Stocks),
+		})
+	}
+
+	return result, nil
+}
+
+func (p *FileStockProvider) open() (fs.FS, func(), error) {
+	if !strings.HasSuffix(strings.ToLower(p.source), ".zip") {
+		return os.DirFS(p.source), func() {}, nil
+	}
+
+	archive, err := zip.OpenReader(p.source)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error opening archive %s: %w", p.source, err)
+	}
+	return archive, func() { archive.Close() }, nil
+}
+
+// pageFiles lists the JSON and CSV files in the source, in lexical order
+func pageFiles(fsys fs.FS) ([]string, error) {
+	var files []string
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		switch strings.ToLower(path.Ext(name)) {
+		case ".json", ".csv":
+			files = append(files, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing stock pages: %w", err)
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+func readPage(fsys fs.FS, name string) ([]stockDTO, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("error opening %s: %w", name, err)
+	}
+	defer file.Close()
+
+	if strings.ToLower(path.Ext(name)) == ".csv" {
+		return readCSVPage(file, name)
+	}
+
+	var page apiResponse
+	if err := json.NewDecoder(file).Decode(&page); err != nil {
+		return nil, fmt.Errorf("error decoding %s: %w", name, err)
+	}
+	return page.Items, nil
+}
+
+func readCSVPage(r io.Reader, name string) ([]stockDTO, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading header of %s: %w", name, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, column := range header {
+		columns[strings.TrimSpace(strings.ToLower(column))] = i
+	}
+	if _, ok := columns["ticker"]; !ok {
+		return nil, fmt.Errorf("%s has no ticker column", name)
+	}
+
+	var items []stockDTO
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", name, err)
+		}
+
+		field := func(column string) string {
+			i, ok := columns[column]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		items = append(items, stockDTO{
+			Ticker:     field("ticker"),
+			TargetFrom: field("target_from"),
+			TargetTo:   field("target_to"),
+			Company:    field("company"),
+			Action:     field("action"),
+			Brokerage:  field("brokerage"),
+			RatingFrom: field("rating_from"),
+			RatingTo:   field("rating_to"),
+			Time:       field("time"),
+		})
+	}
+	return items, nil
+}
@@ -0,0 +1,56 @@
+package stockapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"stockapi/internal/domain/stock"
+	"strings"
+)
+
+// RecordParser re-runs the provider conversion on a single raw record, which
+// is how quarantined records get reprocessed
+type RecordParser struct{}
+
+func NewRecordParser() stock.RecordParser {
+	return &RecordParser{}
+}
+
+func (p *RecordParser) ParseRecord(payload []byte) (*stock.Stock, error) {
+	var item stockDTO
+	if err := json.Unmarshal(payload, &item); err != nil {
+		return nil, fmt.Errorf("error decoding record: %w", err)
+	}
+	return convertToStock(item)
+}
+
+// validateRecord checks the fields every record needs before conversion, so a
+// reject names what is missing or malformed instead of the parse error it
+// would cause
+func validateRecord(item stockDTO) error {
+	var missing []string
+	if strings.TrimSpace(item.Ticker) == "" {
+		missing = append(missing, "ticker")
+	}
+	if strings.TrimSpace(item.TargetFrom) == "" {
+		missing = append(missing, "target_from")
+	}
+	if strings.TrimSpace(item.TargetTo) == "" {
+		missing = append(missing, "target_to")
+	}
+	if strings.TrimSpace(item.RatingTo) == "" {
+		missing = append(missing, "rating_to")
+	}
+	if strings.TrimSpace(item.Time) == "" {
+		missing = append(missing, "time")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing required fields: %s", strings.Join(missing, ", "))
+	}
+	if _, err := stock.ParseTicker(item.Ticker); err != nil {
+		return fmt.Errorf("invalid ticker %q", item.Ticker)
+	}
+	return nil
+}
+
+var errNegativeTarget = errors.New("target price cannot be negative")
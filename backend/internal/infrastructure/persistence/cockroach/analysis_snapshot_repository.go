@@ -0,0 +1,219 @@
+package cockroach
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"stockapi/internal/domain/analysis"
+	"stockapi/internal/domain/shared"
+	"stockapi/internal/domain/stock"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AnalysisSnapshotRepository struct {
+	db     *pgxpool.Pool
+	logger shared.Logger
+}
+
+func NewAnalysisSnapshotRepository(db *pgxpool.Pool, logger shared.Logger) analysis.SnapshotRepository {
+	return &AnalysisSnapshotRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// stockRow is the JSON form of a stock inside a snapshot
+type stockRow struct {
+	StockID    uuid.UUID    `json:"stock_id"`
+	Ticker     stock.Ticker `json:"ticker"`
+	TargetFrom stock.Money  `json:"target_from"`
+	TargetTo   stock.Money  `json:"target_to"`
+	Company    string       `json:"company"`
+	Action     string       `json:"action"`
+	Brokerage  string       `json:"brokerage"`
+	RatingFrom stock.Rating `json:"rating_from"`
+	RatingTo   stock.Rating `json:"rating_to"`
+	Time       time.Time    `json:"time"`
+	Source     string       `json:"source"`
+	// Classification columns are empty for stocks without reference data
+	Sector    string                `json:"sector,omitempty"`
+	Industry  string                `json:"industry,omitempty"`
+	MarketCap stock.MarketCapBucket `json:"market_cap,omitempty"`
+	Exchange  string                `json:"exchange,omitempty"`
+}
+
+// analysisRow is the JSON form of an analysis inside a snapshot
+type analysisRow struct {
+	stockRow
+	Score          float64            `json:"score"`
+	Indicators     map[string]float64 `json:"indicators"`
+	Recommendation string             `json:"recommendation"`
+	LastUpdated    time.Time          `json:"last_updated"`
+}
+
+// exclusionRow is the JSON form of an excluded stock inside a snapshot
+type exclusionRow struct {
+	stockRow
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func toStockRow(s *stock.Stock) stockRow {
+	row := stockRow{
+		StockID:    s.ID,
+		Ticker:     s.Ticker,
+		TargetFrom: s.Target.From,
+		TargetTo:   s.Target.To,
+		Company:    s.Company,
+		Action:     s.Action,
+		Brokerage:  s.Brokerage,
+		RatingFrom: s.Rating.From,
+		RatingTo:   s.Rating.To,
+		Time:       s.Time,
+		Source:     s.Source,
+	}
+	if s.Classification != nil {
+		row.Sector = s.Classification.Sector
+		row.Industry = s.Classification.Industry
+		row.MarketCap = s.Classification.MarketCap
+		row.Exchange = s.Classification.Exchange
+	}
+	return row
+}
+
+func (row stockRow) toStock() *stock.Stock {
+	s := &stock.Stock{
+		ID:        row.StockID,
+		Ticker:    row.Ticker,
+		Target:    stock.TargetPrice{From: row.TargetFrom, To: row.TargetTo},
+		Company:   row.Company,
+		Action:    row.Action,
+		Brokerage: row.Brokerage,
+		Rating:    stock.RatingChange{From: row.RatingFrom, To: row.RatingTo},
+		Time:      row.Time,
+		Source:    row.Source,
+	}
+	if row.Sector != "" {
+		s.Classification = &stock.Classification{
+			Ticker:    row.Ticker,
+			Sector:    row.Sector,
+			Industry:  row.Industry,
+			MarketCap: row.MarketCap,
+			Exchange:  row.Exchange,
+		}
+	}
+	return s
+}
+
+func (r *AnalysisSnapshotRepository) FindSnapshot(ctx context.Context, key string) (*analysis.Snapshot, error) {
+	query := `
+        SELECT cache_key, data_version, computed_at, expires_at, analyses, excluded
+        FROM analysis_snapshots
+        WHERE cache_key = $1
+    `
+
+	var snapshot analysis.Snapshot
+	var encoded, encodedExcluded []byte
+	err := r.db.QueryRow(ctx, query, key).Scan(
+		&snapshot.Key,
+		&snapshot.DataVersion,
+		&snapshot.ComputedAt,
+		&snapshot.ExpiresAt,
+		&encoded,
+		&encodedExcluded,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, stock.ErrSnapshotNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding analysis snapshot: %w", err)
+	}
+
+	var rows []analysisRow
+	if err := json.Unmarshal(encoded, &rows); err != nil {
+		return nil, fmt.Errorf("error decoding analysis snapshot: %w", err)
+	}
+	snapshot.Analyses = make([]analysis.StockAnalysis, len(rows))
+	for i, row := range rows {
+		snapshot.Analyses[i] = analysis.StockAnalysis{
+			Stock:          row.toStock(),
+			Score:          row.Score,
+			Indicators:     row.Indicators,
+			Recommendation: row.Recommendation,
+			LastUpdated:    row.LastUpdated,
+		}
+	}
+
+	var excludedRows []exclusionRow
+	if err := json.Unmarshal(encodedExcluded, &excludedRows); err != nil {
+		return nil, fmt.Errorf("error decoding analysis snapshot exclusions: %w", err)
+	}
+	snapshot.Excluded = make([]analysis.Exclusion, len(excludedRows))
+	for i, row := range excludedRows {
+		snapshot.Excluded[i] = analysis.Exclusion{
+			Stock:  row.toStock(),
+			Reason: &stock.DomainError{Code: row.Code, Message: row.Message},
+		}
+	}
+	return &snapshot, nil
+}
+
+func (r *AnalysisSnapshotRepository) SaveSnapshot(ctx context.Context, snapshot *analysis.Snapshot) error {
+	rows := make([]analysisRow, len(snapshot.Analyses))
+	for i, a := range snapshot.Analyses {
+		rows[i] = analysisRow{
+			stockRow:       toStockRow(a.Stock),
+			Score:          a.Score,
+			Indicators:     a.Indicators,
+			Recommendation: a.Recommendation,
+			LastUpdated:    a.LastUpdated,
+		}
+	}
+	encoded, err := json.Marshal(rows)
+	if err != nil {
+		return fmt.Errorf("error encoding analysis snapshot: %w", err)
+	}
+
+	excludedRows := make([]exclusionRow, len(snapshot.Excluded))
+	for i, e := range snapshot.Excluded {
+		excludedRows[i] = exclusionRow{
+			stockRow: toStockRow(e.Stock),
+			Code:     e.Reason.Code,
+			Message:  e.Reason.Message,
+		}
+	}
+	encodedExcluded, err := json.Marshal(excludedRows)
+	if err != nil {
+		return fmt.Errorf("error encoding analysis snapshot exclusions: %w", err)
+	}
+
+	query := `
+        UPSERT INTO analysis_snapshots (cache_key, data_version, computed_at, expires_at, analyses, excluded)
+        VALUES ($1, $2, $3, $4, $5, $6)
+    `
+
+	_, err = r.db.Exec(ctx, query,
+		snapshot.Key,
+		snapshot.DataVersion,
+		snapshot.ComputedAt,
+		snapshot.ExpiresAt,
+		encoded,
+		encodedExcluded,
+	)
+	if err != nil {
+		return fmt.Errorf("error saving analysis snapshot: %w", err)
+	}
+
+	r.logger.Debug(ctx, "Analysis snapshot saved", map[string]interface{}{
+		"key":          snapshot.Key,
+		"data_version": snapshot.DataVersion,
+		"analyses":     len(snapshot.Analyses),
+		"excluded":     len(snapshot.Excluded),
+	})
+	return nil
+}
@@ -0,0 +1,56 @@
+package cockroach
+
+import (
+	"context"
+	"fmt"
+	"stockapi/internal/domain/shared"
+	"stockapi/internal/domain/stock"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ClassificationRepository struct {
+	db     *pgxpool.Pool
+	logger shared.Logger
+}
+
+func NewClassificationRepository(db *pgxpool.Pool, logger shared.Logger) stock.ClassificationRepository {
+	return &ClassificationRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *ClassificationRepository) SaveClassifications(ctx context.Context, classifications []stock.Classification) error {
+	if len(classifications) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, c := range classifications {
+		batch.Queue(`
+            UPSERT INTO companies (ticker, sector, industry, market_cap, exchange, updated_at)
+            VALUES ($1, $2, $3, $4, $5, $6)
+        `, c.Ticker, c.Sector, c.Industry, string(c.MarketCap), c.Exchange, c.UpdatedAt)
+	}
+
+	// Run the batch in a transaction so an import is applied entirely or not at all
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error starting classification import: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("error saving classifications: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error committing classifications: %w", err)
+	}
+
+	r.logger.Info(ctx, "Classifications saved", map[string]interface{}{
+		"count": len(classifications),
+	})
+	return nil
+}
@@ -0,0 +1,273 @@
+package cockroach
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"stockapi/internal/domain/shared"
+	"stockapi/internal/domain/stock"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// uniqueViolation is the SQLSTATE of a duplicate key
+const uniqueViolation = "23505"
+
+type CorrectionRepository struct {
+	db     *pgxpool.Pool
+	logger shared.Logger
+}
+
+func NewCorrectionRepository(db *pgxpool.Pool, logger shared.Logger) stock.CorrectionRepository {
+	return &CorrectionRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *CorrectionRepository) FindStockByID(ctx context.Context, id uuid.UUID) (*stock.Stock, error) {
+	query := `
+        SELECT ` + stockColumns + `
+        FROM stocks s
+        LEFT JOIN companies c ON c.ticker = s.ticker
+        WHERE s.id = $1
+    `
+
+	s, err := scanStock(r.db.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, stock.ErrStockNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding stock by id: %w", err)
+	}
+	return s, nil
+}
+
+func (r *CorrectionRepository) ApplyCorrection(ctx context.Context, c *stock.Correction) error {
+	before, err := encodeAuditStock(c.Before)
+	if err != nil {
+		return err
+	}
+	after, err := encodeAuditStock(c.After)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := writeCorrectedStock(ctx, tx, c); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return stock.ErrTickerTaken
+		}
+		return fmt.Errorf("error writing corrected stock: %w", err)
+	}
+	if err := writeCorrectedRatingAction(ctx, tx, c); err != nil {
+		return fmt.Errorf("error writing corrected rating action: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+        INSERT INTO stock_corrections (id, stock_id, action, admin, corrected_at, before, after)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+    `, c.ID, c.StockID, c.Action, c.Admin, c.At, before, after)
+	if err != nil {
+		return fmt.Errorf("error saving correction: %w", err)
+	}
+
+	if c.Action == stock.CorrectionRelease {
+		if _, err := tx.Exec(ctx, `DELETE FROM stock_locks WHERE stock_id = $1`, c.StockID); err != nil {
+			return fmt.Errorf("error releasing stock locks: %w", err)
+		}
+	}
+	for _, ticker := range c.LockedTickers() {
+		_, err := tx.Exec(ctx, `
+            UPSERT INTO stock_locks (ticker, stock_id, locked_by, locked_at)
+            VALUES ($1, $2, $3, $4)
+        `, ticker, c.StockID, c.Admin, c.At)
+		if err != nil {
+			return fmt.Errorf("error locking ticker %s: %w", ticker, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error committing correction: %w", err)
+	}
+
+	r.logger.Info(ctx, "Stock correction saved", map[string]interface{}{
+		"stock_id": c.StockID,
+		"action":   c.Action,
+		"admin":    c.Admin,
+	})
+	return nil
+}
+
+// writeCorrectedStock stores the record as it is after the correction
+func writeCorrectedStock(ctx context.Context, tx pgx.Tx, c *stock.Correction) error {
+	var err error
+	switch {
+	case c.Action == stock.CorrectionRelease:
+	case c.After == nil:
+		_, err = tx.Exec(ctx, `DELETE FROM stocks WHERE id = $1`, c.StockID)
+	case c.Before == nil:
+		_, err = tx.Exec(ctx, `
+            INSERT INTO stocks (
+                id, ticker, target_from_amount, target_from_currency,
+                target_to_amount, target_to_currency, company,
+                action, brokerage, rating_from, rating_to, time, source
+            ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+        `, c.After.ID, c.After.Ticker, c.After.Target.From.Amount, c.After.Target.From.Currency,
+			c.After.Target.To.Amount, c.After.Target.To.Currency, c.After.Company,
+			c.After.Action, c.After.Brokerage, c.After.Rating.From, c.After.Rating.To, c.After.Time, c.After.Source)
+	default:
+		_, err = tx.Exec(ctx, `
+            UPDATE stocks SET
+                ticker = $1,
+                target_from_amount = $2,
+                target_from_currency = $3,
+                target_to_amount = $4,
+                target_to_currency = $5,
+                company = $6,
+                action = $7,
+                brokerage = $8,
+                rating_from = $9,
+                rating_to = $10,
+                time = $11,
+                source = $12
+            WHERE id = $13
+        `, c.After.Ticker, c.After.Target.From.Amount, c.After.Target.From.Currency,
+			c.After.Target.To.Amount, c.After.Target.To.Currency, c.After.Company,
+			c.After.Action, c.After.Brokerage, c.After.Rating.From, c.After.Rating.To, c.After.Time, c.After.Source,
+			c.StockID)
+	}
+	return err
+}
+
+// writeCorrectedRatingAction marks the rating action of the record before the
+// correction superseded by it and adds the corrected action as a manual row of
+// its own, so the rating history matches the corrected record while the
+// provider's action stays on file. A release leaves the history as it is.
+func writeCorrectedRatingAction(ctx context.Context, tx pgx.Tx, c *stock.Correction) error {
+	if c.Action == stock.CorrectionRelease {
+		return nil
+	}
+	if c.Before != nil {
+		_, err := tx.Exec(ctx, `
+            UPDATE rating_actions SET superseded_by = $4
+            WHERE ticker = $1 AND brokerage = $2 AND time = $3 AND superseded_by IS NULL
+        `, c.Before.Ticker, c.Before.Brokerage, c.Before.Time, c.ID)
+		if err != nil {
+			return err
+		}
+	}
+	if c.After == nil {
+		return nil
+	}
+	_, err := tx.Exec(ctx, `
+        INSERT INTO rating_actions (
+            ticker, brokerage, time, target_from_amount, target_from_currency,
+            target_to_amount, target_to_currency, company, action,
+            rating_from, rating_to, source, correction_id
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+    `, c.After.Ticker, c.After.Brokerage, c.After.Time, c.After.Target.From.Amount, c.After.Target.From.Currency,
+		c.After.Target.To.Amount, c.After.Target.To.Currency, c.After.Company, c.After.Action,
+		c.After.Rating.From, c.After.Rating.To, stock.ManualSource, c.ID)
+	return err
+}
+
+// encodeAuditStock stores a stock in the audit trail in the same JSON form as
+// analysis snapshots; a nil stock is stored as NULL
+func encodeAuditStock(s *stock.Stock) ([]byte, error) {
+	if s == nil {
+		return nil, nil
+	}
+	encoded, err := json.Marshal(toStockRow(s))
+	if err != nil {
+		return nil, fmt.Errorf("error encoding audited stock: %w", err)
+	}
+	return encoded, nil
+}
+
+func decodeAuditStock(encoded []byte) (*stock.Stock, error) {
+	if encoded == nil {
+		return nil, nil
+	}
+	var row stockRow
+	if err := json.Unmarshal(encoded, &row); err != nil {
+		return nil, fmt.Errorf("error decoding audited stock: %w", err)
+	}
+	return row.toStock(), nil
+}
+
+func (r *CorrectionRepository) FindCorrections(ctx context.Context, stockID uuid.UUID, limit int) ([]stock.Correction, error) {
+	query := `
+        SELECT id, stock_id, action, admin, corrected_at, before, after
+        FROM stock_corrections
+        WHERE stock_id = $1
+        ORDER BY corrected_at DESC
+        LIMIT $2
+    `
+
+	rows, err := r.db.Query(ctx, query, stockID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying corrections: %w", err)
+	}
+	defer rows.Close()
+
+	var corrections []stock.Correction
+	for rows.Next() {
+		var c stock.Correction
+		var before, after []byte
+		if err := rows.Scan(&c.ID, &c.StockID, &c.Action, &c.Admin, &c.At, &before, &after); err != nil {
+			return nil, fmt.Errorf("error scanning correction: %w", err)
+		}
+		if c.Before, err = decodeAuditStock(before); err != nil {
+			return nil, err
+		}
+		if c.After, err = decodeAuditStock(after); err != nil {
+			return nil, err
+		}
+		corrections = append(corrections, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating corrections: %w", err)
+	}
+	return corrections, nil
+}
+
+func (r *CorrectionRepository) FindStockLocks(ctx context.Context, stockID uuid.UUID) ([]stock.StockLock, error) {
+	query := `
+        SELECT ticker, stock_id, locked_by, locked_at
+        FROM stock_locks
+        WHERE $1::UUID IS NULL OR stock_id = $1
+        ORDER BY ticker
+    `
+
+	rows, err := r.db.Query(ctx, query, nullableUUID(stockID))
+	if err != nil {
+		return nil, fmt.Errorf("error querying stock locks: %w", err)
+	}
+	defer rows.Close()
+
+	var locks []stock.StockLock
+	for rows.Next() {
+		var lock stock.StockLock
+		if err := rows.Scan(&lock.Ticker, &lock.StockID, &lock.LockedBy, &lock.LockedAt); err != nil {
+			return nil, fmt.Errorf("error scanning stock lock: %w", err)
+		}
+		locks = append(locks, lock)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating stock locks: %w", err)
+	}
+	return locks, nil
+}
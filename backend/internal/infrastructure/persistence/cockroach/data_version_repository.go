@@ -0,0 +1,62 @@
+package cockroach
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"stockapi/internal/domain/shared"
+	"stockapi/internal/domain/stock"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// stocksDataVersion is the data_versions row tracking the stocks table
+const stocksDataVersion = "stocks"
+
+type DataVersionRepository struct {
+	db     *pgxpool.Pool
+	logger shared.Logger
+}
+
+func NewDataVersionRepository(db *pgxpool.Pool, logger shared.Logger) stock.DataVersionRepository {
+	return &DataVersionRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *DataVersionRepository) CurrentDataVersion(ctx context.Context) (stock.DataVersion, error) {
+	query := `SELECT version, updated_at FROM data_versions WHERE name = $1`
+
+	var version stock.DataVersion
+	err := r.db.QueryRow(ctx, query, stocksDataVersion).Scan(&version.Version, &version.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return stock.DataVersion{}, nil
+	}
+	if err != nil {
+		return stock.DataVersion{}, fmt.Errorf("error reading data version: %w", err)
+	}
+	return version, nil
+}
+
+func (r *DataVersionRepository) BumpDataVersion(ctx context.Context) (stock.DataVersion, error) {
+	query := `
+        INSERT INTO data_versions (name, version, updated_at)
+        VALUES ($1, 1, now())
+        ON CONFLICT (name) DO UPDATE SET
+            version = data_versions.version + 1,
+            updated_at = now()
+        RETURNING version, updated_at
+    `
+
+	var version stock.DataVersion
+	if err := r.db.QueryRow(ctx, query, stocksDataVersion).Scan(&version.Version, &version.UpdatedAt); err != nil {
+		return stock.DataVersion{}, fmt.Errorf("error bumping data version: %w", err)
+	}
+
+	r.logger.Debug(ctx, "Data version bumped", map[string]interface{}{
+		"version": version.Version,
+	})
+	return version, nil
+}
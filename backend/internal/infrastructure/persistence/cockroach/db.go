@@ -0,0 +1,28 @@
+package cockroach
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// NewPool opens the connection pool shared by every repository
+func NewPool(ctx context.Context, dbURL string) (*pgxpool.Pool, error) {
+	dbPool, err := pgxpool.New(ctx, dbURL)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to database: %w", err)
+	}
+	return dbPool, nil
+}
+
+// Migrate applies the schema statements in order. Every statement is
+// idempotent, so it is safe to run on each start.
+func Migrate(ctx context.Context, db *pgxpool.Pool) error {
+	for i, statement := range migrations {
+		if _, err := db.Exec(ctx, statement); err != nil {
+			return fmt.Errorf("error applying migration %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
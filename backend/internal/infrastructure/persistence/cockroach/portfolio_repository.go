@@ -0,0 +1,176 @@
+package cockroach
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"stockapi/internal/domain/portfolio"
+	"stockapi/internal/domain/shared"
+	"stockapi/internal/domain/stock"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PortfolioRepository struct {
+	db     *pgxpool.Pool
+	logger shared.Logger
+}
+
+func NewPortfolioRepository(db *pgxpool.Pool, logger shared.Logger) portfolio.Repository {
+	return &PortfolioRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *PortfolioRepository) SavePortfolio(ctx context.Context, p *portfolio.Portfolio) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+        INSERT INTO portfolios (id, name, kind, max_names, max_weight, max_sector_weight,
+                                min_score, weighting, cash, data_version, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+    `
+
+	_, err = tx.Exec(ctx, query,
+		p.ID,
+		p.Name,
+		p.Kind,
+		p.Constraints.MaxNames,
+		p.Constraints.MaxWeight,
+		p.Constraints.MaxSectorWeight,
+		p.Constraints.MinScore,
+		p.Constraints.Weighting,
+		p.Cash,
+		p.DataVersion,
+		p.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error saving portfolio: %w", err)
+	}
+
+	batch := &pgx.Batch{}
+	for i, h := range p.Holdings {
+		batch.Queue(`
+            INSERT INTO portfolio_holdings (portfolio_id, ticker, stock_id, sector, score, recommendation,
+                                            weight, quantity, cost_basis, position)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+        `, p.ID, h.Ticker, nullableUUID(h.StockID), h.Sector, h.Score, h.Recommendation,
+			h.Weight, h.Quantity, h.CostBasis, i)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("error saving portfolio holdings: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error committing portfolio: %w", err)
+	}
+
+	r.logger.Info(ctx, "Portfolio saved", map[string]interface{}{
+		"portfolio_id": p.ID,
+		"holdings":     len(p.Holdings),
+	})
+	return nil
+}
+
+const portfolioColumns = `id, name, kind, max_names, max_weight, max_sector_weight,
+               min_score, weighting, cash, data_version, created_at`
+
+func scanPortfolio(row pgx.Row) (*portfolio.Portfolio, error) {
+	var p portfolio.Portfolio
+	err := row.Scan(
+		&p.ID,
+		&p.Name,
+		&p.Kind,
+		&p.Constraints.MaxNames,
+		&p.Constraints.MaxWeight,
+		&p.Constraints.MaxSectorWeight,
+		&p.Constraints.MinScore,
+		&p.Constraints.Weighting,
+		&p.Cash,
+		&p.DataVersion,
+		&p.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *PortfolioRepository) FindPortfolio(ctx context.Context, id uuid.UUID) (*portfolio.Portfolio, error) {
+	query := `
+        SELECT ` + portfolioColumns + `
+        FROM portfolios
+        WHERE id = $1
+    `
+
+	p, err := scanPortfolio(r.db.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, stock.ErrPortfolioNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding portfolio: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, `
+        SELECT ticker, stock_id, sector, score, recommendation, weight, quantity, cost_basis
+        FROM portfolio_holdings
+        WHERE portfolio_id = $1
+        ORDER BY position
+    `, id)
+	if err != nil {
+		return nil, fmt.Errorf("error querying portfolio holdings: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var h portfolio.Holding
+		var stockID *uuid.UUID
+		err := rows.Scan(&h.Ticker, &stockID, &h.Sector, &h.Score, &h.Recommendation, &h.Weight, &h.Quantity, &h.CostBasis)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning portfolio holding: %w", err)
+		}
+		if stockID != nil {
+			h.StockID = *stockID
+		}
+		p.Holdings = append(p.Holdings, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating portfolio holdings: %w", err)
+	}
+	return p, nil
+}
+
+func (r *PortfolioRepository) FindPortfolios(ctx context.Context, limit int) ([]portfolio.Portfolio, error) {
+	query := `
+        SELECT ` + portfolioColumns + `
+        FROM portfolios
+        ORDER BY created_at DESC
+        LIMIT $1
+    `
+
+	rows, err := r.db.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying portfolios: %w", err)
+	}
+	defer rows.Close()
+
+	var portfolios []portfolio.Portfolio
+	for rows.Next() {
+		p, err := scanPortfolio(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning portfolio: %w", err)
+		}
+		portfolios = append(portfolios, *p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating portfolios: %w", err)
+	}
+	return portfolios, nil
+}
@@ -0,0 +1,128 @@
+package cockroach
+
+import (
+	"context"
+	"fmt"
+	"stockapi/internal/domain/shared"
+	"stockapi/internal/domain/stock"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type RatingActionRepository struct {
+	db     *pgxpool.Pool
+	logger shared.Logger
+}
+
+func NewRatingActionRepository(db *pgxpool.Pool, logger shared.Logger) stock.RatingActionRepository {
+	return &RatingActionRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *RatingActionRepository) SaveRatingActions(ctx context.Context, actions []*stock.Stock) error {
+	if len(actions) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, a := range actions {
+		batch.Queue(`
+            INSERT INTO rating_actions (
+                ticker, brokerage, time, target_from_amount, target_from_currency,
+                target_to_amount, target_to_currency, company, action,
+                rating_from, rating_to, source
+            )
+            SELECT $1::STRING, $2::STRING, $3::TIMESTAMPTZ, $4::FLOAT8, $5::STRING,
+                   $6::FLOAT8, $7::STRING, $8::STRING, $9::STRING,
+                   $10::STRING, $11::STRING, $12::STRING
+            WHERE NOT EXISTS (SELECT 1 FROM stock_locks WHERE ticker = $1::STRING)
+            ON CONFLICT (ticker, brokerage, time, correction_id) DO NOTHING
+        `,
+			a.Ticker,
+			a.Brokerage,
+			a.Time,
+			a.Target.From.Amount,
+			a.Target.From.Currency,
+			a.Target.To.Amount,
+			a.Target.To.Currency,
+			a.Company,
+			a.Action,
+			a.Rating.From,
+			a.Rating.To,
+			a.Source,
+		)
+	}
+	if err := r.db.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("error saving rating actions: %w", err)
+	}
+
+	r.logger.Debug(ctx, "Rating actions saved", map[string]interface{}{
+		"count": len(actions),
+	})
+	return nil
+}
+
+const ratingActionColumns = `ticker, target_from_amount, target_from_currency,
+               target_to_amount, target_to_currency, company,
+               action, brokerage, rating_from, rating_to, time, source`
+
+func (r *RatingActionRepository) FindRatingActions(ctx context.Context, tickers []stock.Ticker) ([]*stock.Stock, error) {
+	query := `
+        SELECT ` + ratingActionColumns + `
+        FROM rating_actions
+        WHERE ticker = ANY($1) AND superseded_by IS NULL
+        ORDER BY time DESC
+    `
+
+	return r.queryRatingActions(ctx, query, tickers)
+}
+
+func (r *RatingActionRepository) FindRatingActionsSince(ctx context.Context, since time.Time) ([]*stock.Stock, error) {
+	query := `
+        SELECT ` + ratingActionColumns + `
+        FROM rating_actions
+        WHERE time >= $1 AND superseded_by IS NULL
+        ORDER BY time DESC
+    `
+
+	return r.queryRatingActions(ctx, query, since)
+}
+
+func (r *RatingActionRepository) queryRatingActions(ctx context.Context, query string, args ...interface{}) ([]*stock.Stock, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying rating actions: %w", err)
+	}
+	defer rows.Close()
+
+	var actions []*stock.Stock
+	for rows.Next() {
+		var a stock.Stock
+		err := rows.Scan(
+			&a.Ticker,
+			&a.Target.From.Amount,
+			&a.Target.From.Currency,
+			&a.Target.To.Amount,
+			&a.Target.To.Currency,
+			&a.Company,
+			&a.Action,
+			&a.Brokerage,
+			&a.Rating.From,
+			&a.Rating.To,
+			&a.Time,
+			&a.Source,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning rating action: %w", err)
+		}
+		actions = append(actions, &a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rating actions: %w", err)
+	}
+	return actions, nil
+}
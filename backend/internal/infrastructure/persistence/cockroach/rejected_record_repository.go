@@ -0,0 +1,183 @@
+package cockroach
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"stockapi/internal/domain/shared"
+	"stockapi/internal/domain/stock"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type RejectedRecordRepository struct {
+	db     *pgxpool.Pool
+	logger shared.Logger
+}
+
+func NewRejectedRecordRepository(db *pgxpool.Pool, logger shared.Logger) stock.RejectedRecordRepository {
+	return &RejectedRecordRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const rejectedRecordColumns = `id, run_id, source, payload, reason, status, received_at, resolved_at`
+
+// SaveRejected skips records whose source already quarantined the same
+// payload, and returns how many were new
+func (r *RejectedRecordRepository) SaveRejected(ctx context.Context, records []stock.RejectedRecord) (int, error) {
+	if len(records) == 0 {
+		return 0, nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, record := range records {
+		batch.Queue(`
+            INSERT INTO rejected_records (`+rejectedRecordColumns+`, payload_hash)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, sha256($4::JSONB::STRING))
+            ON CONFLICT (source, payload_hash) DO NOTHING
+        `,
+			record.ID,
+			nullableUUID(record.RunID),
+			record.Source,
+			record.Payload,
+			record.Reason,
+			record.Status,
+			record.ReceivedAt,
+			nullableTime(record.ResolvedAt),
+		)
+	}
+
+	results := r.db.SendBatch(ctx, batch)
+	defer results.Close()
+
+	saved := 0
+	for range records {
+		tag, err := results.Exec()
+		if err != nil {
+			return saved, fmt.Errorf("error saving rejected records: %w", err)
+		}
+		saved += int(tag.RowsAffected())
+	}
+	if err := results.Close(); err != nil {
+		return saved, fmt.Errorf("error saving rejected records: %w", err)
+	}
+
+	r.logger.Debug(ctx, "Rejected records saved", map[string]interface{}{
+		"count":      saved,
+		"duplicates": len(records) - saved,
+	})
+	return saved, nil
+}
+
+func (r *RejectedRecordRepository) FindRejected(ctx context.Context, status stock.RejectedStatus, limit int) ([]stock.RejectedRecord, error) {
+	query := `
+        SELECT ` + rejectedRecordColumns + `
+        FROM rejected_records
+        WHERE $1 = '' OR status = $1
+        ORDER BY received_at DESC
+        LIMIT $2
+    `
+
+	rows, err := r.db.Query(ctx, query, string(status), limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying rejected records: %w", err)
+	}
+	defer rows.Close()
+
+	var records []stock.RejectedRecord
+	for rows.Next() {
+		record, err := scanRejectedRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, *record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rejected records: %w", err)
+	}
+	return records, nil
+}
+
+func (r *RejectedRecordRepository) FindRejectedByID(ctx context.Context, id uuid.UUID) (*stock.RejectedRecord, error) {
+	query := `
+        SELECT ` + rejectedRecordColumns + `
+        FROM rejected_records
+        WHERE id = $1
+    `
+
+	record, err := scanRejectedRecord(r.db.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, stock.ErrRejectedRecordNotFound
+	}
+	return record, err
+}
+
+func (r *RejectedRecordRepository) UpdateRejected(ctx context.Context, record *stock.RejectedRecord) error {
+	query := `
+        UPDATE rejected_records SET
+            payload = $1,
+            reason = $2,
+            status = $3,
+            resolved_at = $4
+        WHERE id = $5
+    `
+
+	tag, err := r.db.Exec(ctx, query,
+		record.Payload,
+		record.Reason,
+		record.Status,
+		nullableTime(record.ResolvedAt),
+		record.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating rejected record: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return stock.ErrRejectedRecordNotFound
+	}
+	return nil
+}
+
+func scanRejectedRecord(row pgx.Row) (*stock.RejectedRecord, error) {
+	var record stock.RejectedRecord
+	var runID *uuid.UUID
+	var resolvedAt *time.Time
+	err := row.Scan(
+		&record.ID,
+		&runID,
+		&record.Source,
+		&record.Payload,
+		&record.Reason,
+		&record.Status,
+		&record.ReceivedAt,
+		&resolvedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error scanning rejected record: %w", err)
+	}
+
+	if runID != nil {
+		record.RunID = *runID
+	}
+	if resolvedAt != nil {
+		record.ResolvedAt = *resolvedAt
+	}
+	return &record, nil
+}
+
+// nullableUUID stores the nil UUID as NULL
+func nullableUUID(id uuid.UUID) *uuid.UUID {
+	if id == uuid.Nil {
+		return nil
+	}
+	return &id
+}
@@ -0,0 +1,274 @@
+package cockroach
+
+// migrations holds the schema, oldest first. Append new statements at the end
+// and never edit an applied one.
+var migrations = []string{
+	`CREATE TABLE IF NOT EXISTS stocks (
+        id UUID PRIMARY KEY,
+        ticker STRING NOT NULL,
+        target_from_amount FLOAT8 NOT NULL,
+        target_from_currency STRING NOT NULL,
+        target_to_amount FLOAT8 NOT NULL,
+        target_to_currency STRING NOT NULL,
+        company STRING NOT NULL,
+        action STRING NOT NULL,
+        brokerage STRING NOT NULL,
+        rating_from STRING NOT NULL,
+        rating_to STRING NOT NULL,
+        time TIMESTAMPTZ NOT NULL,
+        INDEX stocks_ticker_idx (ticker),
+        INDEX stocks_time_idx (time DESC)
+    )`,
+
+	// Provider that reported the rating action
+	`ALTER TABLE stocks ADD COLUMN IF NOT EXISTS source STRING NOT NULL DEFAULT ''`,
+
+	// Incremental sync checkpoints, one row per provider
+	`CREATE TABLE IF NOT EXISTS sync_state (
+        source STRING PRIMARY KEY,
+        last_event_time TIMESTAMPTZ,
+        last_full_sync_at TIMESTAMPTZ,
+        updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    )`,
+
+	// Sync run history and the records each run created or changed
+	`CREATE TABLE IF NOT EXISTS sync_runs (
+        id UUID PRIMARY KEY,
+        started_at TIMESTAMPTZ NOT NULL,
+        finished_at TIMESTAMPTZ,
+        status STRING NOT NULL,
+        full_sync BOOL NOT NULL DEFAULT false,
+        fetched INT NOT NULL DEFAULT 0,
+        new_count INT NOT NULL DEFAULT 0,
+        changed_count INT NOT NULL DEFAULT 0,
+        unchanged_count INT NOT NULL DEFAULT 0,
+        upgrades INT NOT NULL DEFAULT 0,
+        downgrades INT NOT NULL DEFAULT 0,
+        target_changes INT NOT NULL DEFAULT 0,
+        brokerage_changes INT NOT NULL DEFAULT 0,
+        error STRING NOT NULL DEFAULT '',
+        INDEX sync_runs_started_at_idx (started_at DESC)
+    )`,
+	`CREATE TABLE IF NOT EXISTS sync_changes (
+        id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+        run_id UUID NOT NULL REFERENCES sync_runs (id) ON DELETE CASCADE,
+        ticker STRING NOT NULL,
+        kind STRING NOT NULL,
+        direction INT NOT NULL DEFAULT 0,
+        fields JSONB NOT NULL DEFAULT '[]',
+        INDEX sync_changes_run_idx (run_id)
+    )`,
+
+	// Provider records quarantined by a sync, kept for reprocessing
+	`ALTER TABLE sync_runs ADD COLUMN IF NOT EXISTS rejected_count INT NOT NULL DEFAULT 0`,
+	`CREATE TABLE IF NOT EXISTS rejected_records (
+        id UUID PRIMARY KEY,
+        run_id UUID REFERENCES sync_runs (id) ON DELETE SET NULL,
+        source STRING NOT NULL,
+        payload JSONB NOT NULL,
+        reason STRING NOT NULL,
+        status STRING NOT NULL DEFAULT 'pending',
+        received_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+        resolved_at TIMESTAMPTZ,
+        INDEX rejected_records_status_idx (status, received_at DESC)
+    )`,
+	// Leases that keep replicas from running the same job concurrently
+	`CREATE TABLE IF NOT EXISTS sync_leases (
+        name STRING PRIMARY KEY,
+        holder STRING NOT NULL,
+        run_id UUID NOT NULL,
+        acquired_at TIMESTAMPTZ NOT NULL,
+        expires_at TIMESTAMPTZ NOT NULL
+    )`,
+	// Version of the stock data, bumped on every write, and the analyses
+	// computed from it
+	`CREATE TABLE IF NOT EXISTS data_versions (
+        name STRING PRIMARY KEY,
+        version INT8 NOT NULL,
+        updated_at TIMESTAMPTZ NOT NULL
+    )`,
+	`CREATE TABLE IF NOT EXISTS analysis_snapshots (
+        cache_key STRING PRIMARY KEY,
+        data_version INT8 NOT NULL,
+        computed_at TIMESTAMPTZ NOT NULL,
+        expires_at TIMESTAMPTZ NOT NULL,
+        analyses JSONB NOT NULL
+    )`,
+	// Per-ticker results of every analysis run
+	`CREATE TABLE IF NOT EXISTS score_history (
+        id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+        ticker STRING NOT NULL,
+        stock_id UUID NOT NULL,
+        score FLOAT8 NOT NULL,
+        indicators JSONB NOT NULL DEFAULT '{}',
+        recommendation STRING NOT NULL,
+        analyzed_at TIMESTAMPTZ NOT NULL,
+        INDEX score_history_ticker_idx (ticker, analyzed_at)
+    )`,
+	// Stocks left out of a snapshot's analyses, with the reason
+	`ALTER TABLE analysis_snapshots ADD COLUMN IF NOT EXISTS excluded JSONB NOT NULL DEFAULT '[]'`,
+	// Company reference data joined into stocks by ticker
+	`CREATE TABLE IF NOT EXISTS companies (
+        ticker STRING PRIMARY KEY,
+        sector STRING NOT NULL,
+        industry STRING NOT NULL DEFAULT '',
+        market_cap STRING NOT NULL DEFAULT '',
+        exchange STRING NOT NULL DEFAULT '',
+        updated_at TIMESTAMPTZ NOT NULL,
+        INDEX companies_sector_idx (sector)
+    )`,
+	// Model portfolios built from the recommendations and their target weights
+	`CREATE TABLE IF NOT EXISTS portfolios (
+        id UUID PRIMARY KEY,
+        name STRING NOT NULL DEFAULT '',
+        max_names INT NOT NULL,
+        max_weight FLOAT8 NOT NULL,
+        max_sector_weight FLOAT8 NOT NULL,
+        min_score FLOAT8 NOT NULL,
+        weighting STRING NOT NULL,
+        cash FLOAT8 NOT NULL,
+        data_version INT8 NOT NULL,
+        created_at TIMESTAMPTZ NOT NULL,
+        INDEX portfolios_created_idx (created_at DESC)
+    )`,
+	`CREATE TABLE IF NOT EXISTS portfolio_holdings (
+        portfolio_id UUID NOT NULL REFERENCES portfolios (id) ON DELETE CASCADE,
+        ticker STRING NOT NULL,
+        stock_id UUID NOT NULL,
+        sector STRING NOT NULL,
+        score FLOAT8 NOT NULL,
+        recommendation STRING NOT NULL,
+        weight FLOAT8 NOT NULL,
+        position INT NOT NULL,
+        PRIMARY KEY (portfolio_id, ticker)
+    )`,
+	// Every rating action seen by a sync, seeded with the latest stored ones.
+	// Migrations run on every start, so the seed only fills an empty table and
+	// never copies manual corrections back in as rating actions.
+	`CREATE TABLE IF NOT EXISTS rating_actions (
+        ticker STRING NOT NULL,
+        brokerage STRING NOT NULL,
+        time TIMESTAMPTZ NOT NULL,
+        target_from_amount FLOAT8 NOT NULL,
+        target_from_currency STRING NOT NULL,
+        target_to_amount FLOAT8 NOT NULL,
+        target_to_currency STRING NOT NULL,
+        company STRING NOT NULL,
+        action STRING NOT NULL,
+        rating_from STRING NOT NULL,
+        rating_to STRING NOT NULL,
+        source STRING NOT NULL DEFAULT '',
+        PRIMARY KEY (ticker, brokerage, time)
+    )`,
+	`INSERT INTO rating_actions (
+        ticker, brokerage, time, target_from_amount, target_from_currency,
+        target_to_amount, target_to_currency, company, action,
+        rating_from, rating_to, source
+    )
+    SELECT ticker, brokerage, time, target_from_amount, target_from_currency,
+           target_to_amount, target_to_currency, company, action,
+           rating_from, rating_to, source
+    FROM stocks
+    WHERE source != 'manual' AND NOT EXISTS (SELECT 1 FROM rating_actions)
+    ON CONFLICT DO NOTHING`,
+	// Uploaded holdings portfolios, with positions instead of target weights
+	`ALTER TABLE portfolios ADD COLUMN IF NOT EXISTS kind STRING NOT NULL DEFAULT 'model'`,
+	`ALTER TABLE portfolio_holdings ADD COLUMN IF NOT EXISTS quantity FLOAT8 NOT NULL DEFAULT 0`,
+	`ALTER TABLE portfolio_holdings ADD COLUMN IF NOT EXISTS cost_basis FLOAT8 NOT NULL DEFAULT 0`,
+	`ALTER TABLE portfolio_holdings ALTER COLUMN stock_id DROP NOT NULL`,
+	// Anomaly detection scans the recent rating actions of every ticker
+	`CREATE INDEX IF NOT EXISTS rating_actions_time_idx ON rating_actions (time DESC)`,
+	// Trigram indexes for the prefix and fuzzy stock search
+	`CREATE INDEX IF NOT EXISTS stocks_ticker_trgm_idx ON stocks USING GIN (ticker gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS stocks_company_trgm_idx ON stocks USING GIN (company gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS stocks_brokerage_trgm_idx ON stocks USING GIN (brokerage gin_trgm_ops)`,
+	// Tickers are stored normalized, trimmed and upper case, and key the stocks
+	// table: the latest record wins when normalizing merges two tickers
+	`UPDATE stocks SET ticker = upper(trim(ticker)) WHERE ticker != upper(trim(ticker))`,
+	`DELETE FROM stocks WHERE id IN (
+        SELECT id FROM (
+            SELECT id, row_number() OVER (PARTITION BY ticker ORDER BY time DESC) AS n
+            FROM stocks
+        ) AS ranked
+        WHERE n > 1
+    )`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS stocks_ticker_key ON stocks (ticker)`,
+	`INSERT INTO rating_actions (
+        ticker, brokerage, time, target_from_amount, target_from_currency,
+        target_to_amount, target_to_currency, company, action,
+        rating_from, rating_to, source
+    )
+    SELECT upper(trim(ticker)), brokerage, time, target_from_amount, target_from_currency,
+           target_to_amount, target_to_currency, company, action,
+           rating_from, rating_to, source
+    FROM rating_actions
+    WHERE ticker != upper(trim(ticker))
+    ON CONFLICT DO NOTHING`,
+	`DELETE FROM rating_actions WHERE ticker != upper(trim(ticker))`,
+	`UPDATE score_history SET ticker = upper(trim(ticker)) WHERE ticker != upper(trim(ticker))`,
+	// Manual corrections of stock records with their before and after state,
+	// and the tickers they protect from syncs until released
+	`CREATE TABLE IF NOT EXISTS stock_corrections (
+        id UUID PRIMARY KEY,
+        stock_id UUID NOT NULL,
+        action STRING NOT NULL,
+        admin STRING NOT NULL,
+        corrected_at TIMESTAMPTZ NOT NULL,
+        before JSONB,
+        after JSONB,
+        INDEX stock_corrections_stock_idx (stock_id, corrected_at DESC)
+    )`,
+	`CREATE TABLE IF NOT EXISTS stock_locks (
+        ticker STRING PRIMARY KEY,
+        stock_id UUID NOT NULL,
+        locked_by STRING NOT NULL,
+        locked_at TIMESTAMPTZ NOT NULL,
+        INDEX stock_locks_stock_idx (stock_id)
+    )`,
+	`ALTER TABLE sync_runs ADD COLUMN IF NOT EXISTS locked_count INT NOT NULL DEFAULT 0`,
+	// A provider record is quarantined once per source, whatever its status,
+	// so full crawls do not queue the same payload again. The hash is taken
+	// from the payload as received and kept when a reprocess corrects it.
+	`ALTER TABLE rejected_records ADD COLUMN IF NOT EXISTS payload_hash STRING`,
+	`UPDATE rejected_records SET payload_hash = sha256(payload::STRING) WHERE payload_hash IS NULL`,
+	`DELETE FROM rejected_records WHERE id IN (
+        SELECT id FROM (
+            SELECT id, row_number() OVER (
+                PARTITION BY source, payload_hash
+                ORDER BY status = 'pending', received_at
+            ) AS n
+            FROM rejected_records
+        ) AS ranked
+        WHERE n > 1
+    )`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS rejected_records_payload_key ON rejected_records (source, payload_hash)`,
+	// The stock data version each point scored; points written before the
+	// column existed keep a NULL version. The analyzed_at index serves the
+	// retention prune.
+	`ALTER TABLE score_history ADD COLUMN IF NOT EXISTS data_version INT8`,
+	`CREATE INDEX IF NOT EXISTS score_history_analyzed_idx ON score_history (analyzed_at)`,
+	// Search matches the brokerages of every rating action, not only the latest
+	`CREATE INDEX IF NOT EXISTS rating_actions_brokerage_trgm_idx ON rating_actions USING GIN (brokerage gin_trgm_ops)`,
+	// Scores change with decay and staleness while the data version stays
+	// the same, so history keeps the latest point of each ticker and UTC day
+	// instead of one per version. Existing points are reduced to their day's
+	// latest before the key is created.
+	`DROP INDEX IF EXISTS score_history@score_history_version_key CASCADE`,
+	`ALTER TABLE score_history ADD COLUMN IF NOT EXISTS recorded_on DATE`,
+	`UPDATE score_history SET recorded_on = (analyzed_at AT TIME ZONE 'UTC')::DATE WHERE recorded_on IS NULL`,
+	`DELETE FROM score_history h WHERE EXISTS (
+        SELECT 1 FROM score_history n
+        WHERE n.ticker = h.ticker AND n.recorded_on = h.recorded_on
+            AND (n.analyzed_at, n.id) > (h.analyzed_at, h.id)
+    )`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS score_history_day_key ON score_history (ticker, recorded_on)`,
+	// Corrections keep the provider's rating action, marked superseded by the
+	// correction, and add their own action keyed by the correction ID, while
+	// provider actions keep the nil UUID. The inserts above conflict on any
+	// key so they run against both keys. Altering the key leaves a unique
+	// index on the old one, which is dropped.
+	`ALTER TABLE rating_actions ADD COLUMN IF NOT EXISTS correction_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000'`,
+	`ALTER TABLE rating_actions ADD COLUMN IF NOT EXISTS superseded_by UUID`,
+	`ALTER TABLE rating_actions ALTER PRIMARY KEY USING COLUMNS (ticker, brokerage, time, correction_id)`,
+	`DROP INDEX IF EXISTS rating_actions@rating_actions_ticker_brokerage_time_key CASCADE`,
+}
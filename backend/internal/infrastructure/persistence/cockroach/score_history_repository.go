@@ -0,0 +1,116 @@
+package cockroach
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"stockapi/internal/domain/analysis"
+	"stockapi/internal/domain/shared"
+	"stockapi/internal/domain/stock"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ScoreHistoryRepository struct {
+	db     *pgxpool.Pool
+	logger shared.Logger
+}
+
+func NewScoreHistoryRepository(db *pgxpool.Pool, logger shared.Logger) analysis.ScoreHistoryRepository {
+	return &ScoreHistoryRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *ScoreHistoryRepository) SaveScores(ctx context.Context, points []analysis.ScorePoint) error {
+	if len(points) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, point := range points {
+		indicators, err := json.Marshal(point.Indicators)
+		if err != nil {
+			return fmt.Errorf("error encoding indicators: %w", err)
+		}
+		batch.Queue(`
+            INSERT INTO score_history (ticker, stock_id, score, indicators, recommendation, analyzed_at, data_version, recorded_on)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, ($6::TIMESTAMPTZ AT TIME ZONE 'UTC')::DATE)
+            ON CONFLICT (ticker, recorded_on) DO UPDATE SET
+                stock_id = excluded.stock_id,
+                score = excluded.score,
+                indicators = excluded.indicators,
+                recommendation = excluded.recommendation,
+                analyzed_at = excluded.analyzed_at,
+                data_version = excluded.data_version
+            WHERE excluded.analyzed_at > score_history.analyzed_at
+        `, point.Ticker, point.StockID, point.Score, indicators, point.Recommendation, point.AnalyzedAt, point.DataVersion)
+	}
+
+	if err := r.db.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("error saving score history: %w", err)
+	}
+
+	r.logger.Debug(ctx, "Score history saved", map[string]interface{}{
+		"points": len(points),
+	})
+	return nil
+}
+
+func (r *ScoreHistoryRepository) FindScores(ctx context.Context, ticker stock.Ticker, from, to time.Time) ([]analysis.ScorePoint, error) {
+	query := `
+        SELECT ticker, stock_id, score, indicators, recommendation, analyzed_at, COALESCE(data_version, 0)
+        FROM score_history
+        WHERE ticker = $1 AND analyzed_at BETWEEN $2 AND $3
+        ORDER BY analyzed_at
+    `
+
+	rows, err := r.db.Query(ctx, query, ticker, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error querying score history: %w", err)
+	}
+	defer rows.Close()
+
+	var points []analysis.ScorePoint
+	for rows.Next() {
+		var point analysis.ScorePoint
+		var indicators []byte
+		err := rows.Scan(
+			&point.Ticker,
+			&point.StockID,
+			&point.Score,
+			&indicators,
+			&point.Recommendation,
+			&point.AnalyzedAt,
+			&point.DataVersion,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning score point: %w", err)
+		}
+		if err := json.Unmarshal(indicators, &point.Indicators); err != nil {
+			return nil, fmt.Errorf("error decoding indicators: %w", err)
+		}
+		points = append(points, point)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating score history: %w", err)
+	}
+	return points, nil
+}
+
+func (r *ScoreHistoryRepository) PruneScores(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM score_history WHERE analyzed_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("error pruning score history: %w", err)
+	}
+
+	r.logger.Debug(ctx, "Score history pruned", map[string]interface{}{
+		"before":  before,
+		"removed": tag.RowsAffected(),
+	})
+	return tag.RowsAffected(), nil
+}
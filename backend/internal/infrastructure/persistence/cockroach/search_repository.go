@@ -0,0 +1,119 @@
+package cockroach
+
+import (
+	"context"
+	"fmt"
+	"stockapi/internal/domain/shared"
+	"stockapi/internal/domain/stock"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SearchRepository struct {
+	db     *pgxpool.Pool
+	logger shared.Logger
+}
+
+func NewSearchRepository(db *pgxpool.Pool, logger shared.Logger) stock.SearchRepository {
+	return &SearchRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// likeEscaper escapes the LIKE wildcards of a user query
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Trigrams barely overlap on short strings, so queries of typo length are
+// also matched against tickers within one edit
+const (
+	minTypoQuery = 3
+	maxTypoQuery = 5
+)
+
+// Search ranks each field on its own: exact and prefix matches rank first,
+// then substring matches, then trigram similarity, which is what tolerates
+// typos. Short queries also match tickers one edit away. Brokerages are
+// matched against every rating action of the stock and the best one counts.
+// The trigram indexes on the searched columns serve the % and ILIKE filters.
+func (r *SearchRepository) Search(ctx context.Context, query string, limit int) ([]stock.SearchHit, error) {
+	sql := `
+        WITH brokerages AS (
+            SELECT ticker, brokerage FROM rating_actions
+            WHERE (brokerage ILIKE '%' || $2 || '%' OR brokerage % $1) AND superseded_by IS NULL
+            UNION
+            SELECT ticker, brokerage FROM stocks
+            WHERE brokerage ILIKE '%' || $2 || '%' OR brokerage % $1
+        ),
+        brokers AS (
+            SELECT DISTINCT ON (ticker) ticker, brokerage, brokerage_rank
+            FROM (
+                SELECT ticker, brokerage,
+                    CASE WHEN brokerage ILIKE $2 || '%' THEN 0.5
+                         WHEN brokerage ILIKE '%' || $2 || '%' THEN 0.4
+                         ELSE similarity(brokerage, $1) * 0.4 END AS brokerage_rank
+                FROM brokerages
+            ) AS ranked
+            ORDER BY ticker, brokerage_rank DESC, brokerage
+        )
+        SELECT ` + stockColumns + `, m.ticker_rank, m.company_rank, m.brokerage_rank, m.brokerage
+        FROM (
+            SELECT s.id,
+                CASE WHEN s.ticker = upper($1) THEN 1.0
+                     WHEN s.ticker LIKE upper($2) || '%' THEN 0.9
+                     WHEN length($1) BETWEEN $4 AND $5 AND levenshtein(s.ticker, upper($1)) <= 1 THEN 0.6
+                     ELSE similarity(s.ticker, $1) * 0.7 END AS ticker_rank,
+                CASE WHEN s.company ILIKE $2 || '%' THEN 0.85
+                     WHEN s.company ILIKE '% ' || $2 || '%' THEN 0.75
+                     WHEN s.company ILIKE '%' || $2 || '%' THEN 0.6
+                     ELSE similarity(s.company, $1) * 0.7 END AS company_rank,
+                COALESCE(b.brokerage_rank, 0) AS brokerage_rank,
+                COALESCE(b.brokerage, '') AS brokerage
+            FROM stocks s
+            LEFT JOIN brokers b ON b.ticker = s.ticker
+            WHERE s.ticker LIKE upper($2) || '%'
+               OR s.ticker % $1
+               OR (length($1) BETWEEN $4 AND $5 AND levenshtein(s.ticker, upper($1)) <= 1)
+               OR s.company ILIKE '%' || $2 || '%'
+               OR s.company % $1
+               OR b.ticker IS NOT NULL
+        ) m
+        JOIN stocks s ON s.id = m.id
+        LEFT JOIN companies c ON c.ticker = s.ticker
+        ORDER BY greatest(m.ticker_rank, m.company_rank, m.brokerage_rank) DESC, s.ticker
+        LIMIT $3
+    `
+
+	rows, err := r.db.Query(ctx, sql, query, likeEscaper.Replace(query), limit, minTypoQuery, maxTypoQuery)
+	if err != nil {
+		return nil, fmt.Errorf("error searching stocks: %w", err)
+	}
+	defer rows.Close()
+
+	var hits []stock.SearchHit
+	for rows.Next() {
+		var tickerRank, companyRank, brokerageRank float64
+		var brokerage string
+		s, err := scanStock(rows, &tickerRank, &companyRank, &brokerageRank, &brokerage)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning search hit: %w", err)
+		}
+
+		hit := stock.SearchHit{Stock: s, Field: stock.SearchTicker, Rank: tickerRank}
+		if companyRank > hit.Rank {
+			hit.Field, hit.Rank = stock.SearchCompany, companyRank
+		}
+		if brokerageRank > hit.Rank {
+			hit.Field, hit.Rank = stock.SearchBrokerage, brokerageRank
+		}
+		if brokerageRank > 0 {
+			hit.Brokerage = brokerage
+		}
+		hits = append(hits, hit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating search hits: %w", err)
+	}
+	return hits, nil
+}
@@ -2,10 +2,13 @@ package cockroach
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"stockapi/internal/domain/shared"
 	"stockapi/internal/domain/stock"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -14,124 +17,149 @@ type StockRepository struct {
 	logger shared.Logger
 }
 
-func NewStockRepository(ctx context.Context, dbURL string, logger shared.Logger) (stock.Repository, error) {
-	dbPool, err := pgxpool.New(ctx, dbURL)
-	if err != nil {
-		return nil, fmt.Errorf("unable to connect to database: %w", err)
-	}
-
+func NewStockRepository(db *pgxpool.Pool, logger shared.Logger) stock.Repository {
 	return &StockRepository{
-		db:     dbPool,
+		db:     db,
 		logger: logger,
-	}, nil
-}
-
-func (r *StockRepository) Save(ctx context.Context, stock *stock.Stock) error {
-	// First we check if the stock already exists
-	existingStock, err := r.FindByTicker(ctx, stock.Ticker)
-	if err == nil && existingStock != nil {
-		// The stock exists, we update instead of inserting
-		r.logger.Debug(ctx, "Stock already exists, updating", map[string]interface{}{
-			"ticker": stock.Ticker,
-			"id":     stock.ID,
-		})
-
-		return r.Update(ctx, stock)
 	}
+}
 
-	// If it doesn't exist or there was an error searching (not found), we proceed with the insertion
-	r.logger.Debug(ctx, "Inserting new stock", map[string]interface{}{
-		"ticker": stock.Ticker,
-		"id":     stock.ID,
-	})
-
+// Save inserts the stock, or updates the stored record of its ticker in the
+// same statement so concurrent writers never race between lookup and insert.
+// The stock takes the ID of the stored record it replaces. The lock check is
+// part of the statement too, so a manual correction that locks the ticker
+// after the caller planned the write still wins: nothing is written and
+// stock.ErrStockLocked is returned.
+func (r *StockRepository) Save(ctx context.Context, stk *stock.Stock) error {
 	query := `
         INSERT INTO stocks (
             id, ticker, target_from_amount, target_from_currency,
             target_to_amount, target_to_currency, company,
-            action, brokerage, rating_from, rating_to, time
-        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+            action, brokerage, rating_from, rating_to, time, source
+        )
+        SELECT $1::UUID, $2::STRING, $3::FLOAT8, $4::STRING, $5::FLOAT8, $6::STRING, $7::STRING,
+               $8::STRING, $9::STRING, $10::STRING, $11::STRING, $12::TIMESTAMPTZ, $13::STRING
+        WHERE NOT EXISTS (SELECT 1 FROM stock_locks WHERE ticker = $2::STRING)
+        ON CONFLICT (ticker) DO UPDATE SET
+            target_from_amount = excluded.target_from_amount,
+            target_from_currency = excluded.target_from_currency,
+            target_to_amount = excluded.target_to_amount,
+            target_to_currency = excluded.target_to_currency,
+            company = excluded.company,
+            action = excluded.action,
+            brokerage = excluded.brokerage,
+            rating_from = excluded.rating_from,
+            rating_to = excluded.rating_to,
+            time = excluded.time,
+            source = excluded.source
+        RETURNING id
     `
 
-	_, err = r.db.Exec(ctx, query,
-		stock.ID,
-		stock.Ticker,
-		stock.Target.From.Amount,
-		stock.Target.From.Currency,
-		stock.Target.To.Amount,
-		stock.Target.To.Currency,
-		stock.Company,
-		stock.Action,
-		stock.Brokerage,
-		stock.Rating.From,
-		stock.Rating.To,
-		stock.Time,
-	)
-
+	err := r.db.QueryRow(ctx, query,
+		stk.ID,
+		stk.Ticker,
+		stk.Target.From.Amount,
+		stk.Target.From.Currency,
+		stk.Target.To.Amount,
+		stk.Target.To.Currency,
+		stk.Company,
+		stk.Action,
+		stk.Brokerage,
+		stk.Rating.From,
+		stk.Rating.To,
+		stk.Time,
+		stk.Source,
+	).Scan(&stk.ID)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return stock.ErrStockLocked
+	}
 	if err != nil {
 		return fmt.Errorf("error saving stock: %w", err)
 	}
 
 	r.logger.Info(ctx, "Stock saved successfully", map[string]interface{}{
-		"ticker": stock.Ticker,
-		"id":     stock.ID,
+		"ticker": stk.Ticker,
+		"id":     stk.ID,
 	})
 	return nil
 }
 
 func (r *StockRepository) FindAll(ctx context.Context) ([]*stock.Stock, error) {
+	var stocks []*stock.Stock
+	err := r.Iterate(ctx, func(s *stock.Stock) error {
+		stocks = append(stocks, s)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stocks, nil
+}
+
+func (r *StockRepository) Iterate(ctx context.Context, fn func(*stock.Stock) error) error {
 	query := `
-        SELECT id, ticker, target_from_amount, target_from_currency,
-               target_to_amount, target_to_currency, company,
-               action, brokerage, rating_from, rating_to, time
-        FROM stocks
-        ORDER BY time DESC
+        SELECT ` + stockColumns + `
+        FROM stocks s
+        LEFT JOIN companies c ON c.ticker = s.ticker
+        ORDER BY s.time DESC
     `
 
 	rows, err := r.db.Query(ctx, query)
 	if err != nil {
-		return nil, fmt.Errorf("error querying stocks: %w", err)
+		return fmt.Errorf("error querying stocks: %w", err)
 	}
 	defer rows.Close()
 
-	var stocks []*stock.Stock
 	for rows.Next() {
-		var s stock.Stock
-		err := rows.Scan(
-			&s.ID,
-			&s.Ticker,
-			&s.Target.From.Amount,
-			&s.Target.From.Currency,
-			&s.Target.To.Amount,
-			&s.Target.To.Currency,
-			&s.Company,
-			&s.Action,
-			&s.Brokerage,
-			&s.Rating.From,
-			&s.Rating.To,
-			&s.Time,
-		)
+		s, err := scanStock(rows)
 		if err != nil {
-			return nil, fmt.Errorf("error scanning stock: %w", err)
+			return fmt.Errorf("error scanning stock: %w", err)
+		}
+		if err := fn(s); err != nil {
+			return err
 		}
-		stocks = append(stocks, &s)
 	}
 
-	return stocks, nil
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating stocks: %w", err)
+	}
+	return nil
 }
 
-func (r *StockRepository) FindByTicker(ctx context.Context, ticker string) (*stock.Stock, error) {
+func (r *StockRepository) FindByTicker(ctx context.Context, ticker stock.Ticker) (*stock.Stock, error) {
 	query := `
-        SELECT id, ticker, target_from_amount, target_from_currency,
-               target_to_amount, target_to_currency, company,
-               action, brokerage, rating_from, rating_to, time
-        FROM stocks
-        WHERE ticker = $1
+        SELECT ` + stockColumns + `
+        FROM stocks s
+        LEFT JOIN companies c ON c.ticker = s.ticker
+        WHERE s.ticker = $1
         LIMIT 1
     `
 
+	s, err := scanStock(r.db.QueryRow(ctx, query, ticker))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, stock.ErrStockNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding stock by ticker: %w", err)
+	}
+
+	return s, nil
+}
+
+// stockColumns selects a stock joined with its company classification
+const stockColumns = `s.id, s.ticker, s.target_from_amount, s.target_from_currency,
+               s.target_to_amount, s.target_to_currency, s.company,
+               s.action, s.brokerage, s.rating_from, s.rating_to, s.time, s.source,
+               c.sector, c.industry, c.market_cap, c.exchange, c.updated_at`
+
+// scanStock reads a row selected with stockColumns, followed by the extra
+// columns scanned into extra
+func scanStock(row pgx.Row, extra ...interface{}) (*stock.Stock, error) {
 	var s stock.Stock
-	err := r.db.QueryRow(ctx, query, ticker).Scan(
+	var sector, industry, marketCap, exchange *string
+	var classifiedAt *time.Time
+	dest := []interface{}{
 		&s.ID,
 		&s.Ticker,
 		&s.Target.From.Amount,
@@ -144,12 +172,27 @@ func (r *StockRepository) FindByTicker(ctx context.Context, ticker string) (*sto
 		&s.Rating.From,
 		&s.Rating.To,
 		&s.Time,
-	)
-
-	if err != nil {
-		return nil, fmt.Errorf("error finding stock by ticker: %w", err)
+		&s.Source,
+		&sector,
+		&industry,
+		&marketCap,
+		&exchange,
+		&classifiedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
 	}
 
+	if sector != nil {
+		s.Classification = &stock.Classification{
+			Ticker:    s.Ticker,
+			Sector:    *sector,
+			Industry:  *industry,
+			MarketCap: stock.MarketCapBucket(*marketCap),
+			Exchange:  *exchange,
+			UpdatedAt: *classifiedAt,
+		}
+	}
 	return &s, nil
 }
 
@@ -165,8 +208,9 @@ func (r *StockRepository) Update(ctx context.Context, stock *stock.Stock) error
             brokerage = $7,
             rating_from = $8,
             rating_to = $9,
-            time = $10
-        WHERE id = $11
+            time = $10,
+            source = $11
+        WHERE id = $12
     `
 
 	_, err := r.db.Exec(ctx, query,
@@ -180,6 +224,7 @@ func (r *StockRepository) Update(ctx context.Context, stock *stock.Stock) error
 		stock.Rating.From,
 		stock.Rating.To,
 		stock.Time,
+		stock.Source,
 		stock.ID,
 	)
 
@@ -190,6 +235,10 @@ func (r *StockRepository) Update(ctx context.Context, stock *stock.Stock) error
 	return nil
 }
 
+func (r *StockRepository) Ping(ctx context.Context) error {
+	return r.db.Ping(ctx)
+}
+
 func (r *StockRepository) Close(ctx context.Context) error {
 	r.db.Close()
 	return nil
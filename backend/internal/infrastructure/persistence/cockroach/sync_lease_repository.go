@@ -0,0 +1,116 @@
+package cockroach
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"stockapi/internal/domain/shared"
+	"stockapi/internal/domain/stock"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SyncLeaseRepository keeps leases in the database so every replica sees the
+// same holder. Expiry uses the database clock to be immune to replica skew.
+type SyncLeaseRepository struct {
+	db     *pgxpool.Pool
+	logger shared.Logger
+}
+
+func NewSyncLeaseRepository(db *pgxpool.Pool, logger shared.Logger) stock.SyncLeaseRepository {
+	return &SyncLeaseRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *SyncLeaseRepository) AcquireLease(ctx context.Context, lease *stock.SyncLease, ttl time.Duration) error {
+	query := `
+        INSERT INTO sync_leases (name, holder, run_id, acquired_at, expires_at)
+        VALUES ($1, $2, $3, now(), now() + $4::INT8 * INTERVAL '1 millisecond')
+        ON CONFLICT (name) DO UPDATE SET
+            holder = excluded.holder,
+            run_id = excluded.run_id,
+            acquired_at = excluded.acquired_at,
+            expires_at = excluded.expires_at
+        WHERE sync_leases.expires_at < now()
+        RETURNING acquired_at, expires_at
+    `
+
+	err := r.db.QueryRow(ctx, query, lease.Name, lease.Holder, lease.RunID, ttl.Milliseconds()).
+		Scan(&lease.AcquiredAt, &lease.ExpiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		current, err := r.findLease(ctx, lease.Name)
+		if err != nil {
+			return err
+		}
+		return &stock.SyncInProgressError{Lease: current}
+	}
+	if err != nil {
+		return fmt.Errorf("error acquiring lease %q: %w", lease.Name, err)
+	}
+
+	r.logger.Debug(ctx, "Lease acquired", map[string]interface{}{
+		"name":       lease.Name,
+		"holder":     lease.Holder,
+		"run_id":     lease.RunID,
+		"expires_at": lease.ExpiresAt,
+	})
+	return nil
+}
+
+func (r *SyncLeaseRepository) RenewLease(ctx context.Context, lease *stock.SyncLease, ttl time.Duration) error {
+	query := `
+        UPDATE sync_leases
+        SET expires_at = now() + $3::INT8 * INTERVAL '1 millisecond'
+        WHERE name = $1 AND run_id = $2 AND expires_at >= now()
+        RETURNING expires_at
+    `
+
+	err := r.db.QueryRow(ctx, query, lease.Name, lease.RunID, ttl.Milliseconds()).Scan(&lease.ExpiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return stock.ErrSyncLeaseLost
+	}
+	if err != nil {
+		return fmt.Errorf("error renewing lease %q: %w", lease.Name, err)
+	}
+	return nil
+}
+
+func (r *SyncLeaseRepository) ReleaseLease(ctx context.Context, lease *stock.SyncLease) error {
+	query := `DELETE FROM sync_leases WHERE name = $1 AND run_id = $2`
+
+	if _, err := r.db.Exec(ctx, query, lease.Name, lease.RunID); err != nil {
+		return fmt.Errorf("error releasing lease %q: %w", lease.Name, err)
+	}
+	return nil
+}
+
+// findLease reads the current holder of a lease. The lease may have been
+// released between the failed acquire and this read, which still means the
+// caller lost the race.
+func (r *SyncLeaseRepository) findLease(ctx context.Context, name string) (*stock.SyncLease, error) {
+	query := `
+        SELECT name, holder, run_id, acquired_at, expires_at
+        FROM sync_leases
+        WHERE name = $1
+    `
+
+	var lease stock.SyncLease
+	err := r.db.QueryRow(ctx, query, name).Scan(
+		&lease.Name,
+		&lease.Holder,
+		&lease.RunID,
+		&lease.AcquiredAt,
+		&lease.ExpiresAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &stock.SyncLease{Name: name}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding lease %q: %w", name, err)
+	}
+	return &lease, nil
+}
@@ -0,0 +1,209 @@
+package cockroach
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"stockapi/internal/domain/shared"
+	"stockapi/internal/domain/stock"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SyncRunRepository struct {
+	db     *pgxpool.Pool
+	logger shared.Logger
+}
+
+func NewSyncRunRepository(db *pgxpool.Pool, logger shared.Logger) stock.SyncRunRepository {
+	return &SyncRunRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+type fieldChangeRow struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+func (r *SyncRunRepository) CreateSyncRun(ctx context.Context, run *stock.SyncRun) error {
+	query := `
+        INSERT INTO sync_runs (id, started_at, status, full_sync)
+        VALUES ($1, $2, $3, $4)
+    `
+
+	if _, err := r.db.Exec(ctx, query, run.ID, run.StartedAt, run.Status, run.Full); err != nil {
+		return fmt.Errorf("error creating sync run: %w", err)
+	}
+	return nil
+}
+
+func (r *SyncRunRepository) FinishSyncRun(ctx context.Context, run *stock.SyncRun, changes []stock.StockChange) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+        UPDATE sync_runs SET
+            finished_at = $1,
+            status = $2,
+            fetched = $3,
+            new_count = $4,
+            changed_count = $5,
+            unchanged_count = $6,
+            upgrades = $7,
+            downgrades = $8,
+            target_changes = $9,
+            brokerage_changes = $10,
+            rejected_count = $11,
+            locked_count = $12,
+            error = $13
+        WHERE id = $14
+    `
+
+	_, err = tx.Exec(ctx, query,
+		run.FinishedAt,
+		run.Status,
+		run.Fetched,
+		run.Summary.New,
+		run.Summary.Changed,
+		run.Summary.Unchanged,
+		run.Summary.Upgrades,
+		run.Summary.Downgrades,
+		run.Summary.TargetChanges,
+		run.Summary.BrokerageChanges,
+		run.Rejected,
+		run.Summary.Locked,
+		run.Error,
+		run.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("error finishing sync run: %w", err)
+	}
+
+	batch := &pgx.Batch{}
+	for _, change := range changes {
+		if change.Kind == stock.ChangeUnchanged {
+			continue
+		}
+
+		fields := make([]fieldChangeRow, len(change.Fields))
+		for i, field := range change.Fields {
+			fields[i] = fieldChangeRow{Field: field.Field, From: field.From, To: field.To}
+		}
+		encoded, err := json.Marshal(fields)
+		if err != nil {
+			return fmt.Errorf("error encoding change fields: %w", err)
+		}
+
+		batch.Queue(`
+            INSERT INTO sync_changes (run_id, ticker, kind, direction, fields)
+            VALUES ($1, $2, $3, $4, $5)
+        `, run.ID, change.Ticker, change.Kind, change.Direction, encoded)
+	}
+
+	if batch.Len() > 0 {
+		if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+			return fmt.Errorf("error saving sync changes: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error committing sync run: %w", err)
+	}
+
+	r.logger.Debug(ctx, "Sync run saved", map[string]interface{}{
+		"run_id": run.ID,
+		"status": run.Status,
+	})
+	return nil
+}
+
+func (r *SyncRunRepository) FindSyncRun(ctx context.Context, id uuid.UUID) (*stock.SyncRun, error) {
+	query := `
+        SELECT id, started_at, finished_at, status, full_sync, fetched,
+               new_count, changed_count, unchanged_count, upgrades,
+               downgrades, target_changes, brokerage_changes, rejected_count,
+               locked_count, error
+        FROM sync_runs
+        WHERE id = $1
+    `
+
+	var run stock.SyncRun
+	var finishedAt *time.Time
+	err := r.db.QueryRow(ctx, query, id).Scan(
+		&run.ID,
+		&run.StartedAt,
+		&finishedAt,
+		&run.Status,
+		&run.Full,
+		&run.Fetched,
+		&run.Summary.New,
+		&run.Summary.Changed,
+		&run.Summary.Unchanged,
+		&run.Summary.Upgrades,
+		&run.Summary.Downgrades,
+		&run.Summary.TargetChanges,
+		&run.Summary.BrokerageChanges,
+		&run.Rejected,
+		&run.Summary.Locked,
+		&run.Error,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, stock.ErrSyncRunNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding sync run: %w", err)
+	}
+
+	if finishedAt != nil {
+		run.FinishedAt = *finishedAt
+	}
+	return &run, nil
+}
+
+func (r *SyncRunRepository) FindSyncChanges(ctx context.Context, runID uuid.UUID) ([]stock.StockChange, error) {
+	query := `
+        SELECT ticker, kind, direction, fields
+        FROM sync_changes
+        WHERE run_id = $1
+        ORDER BY ticker
+    `
+
+	rows, err := r.db.Query(ctx, query, runID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying sync changes: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []stock.StockChange
+	for rows.Next() {
+		var change stock.StockChange
+		var encoded []byte
+		if err := rows.Scan(&change.Ticker, &change.Kind, &change.Direction, &encoded); err != nil {
+			return nil, fmt.Errorf("error scanning sync change: %w", err)
+		}
+
+		var fields []fieldChangeRow
+		if err := json.Unmarshal(encoded, &fields); err != nil {
+			return nil, fmt.Errorf("error decoding change fields: %w", err)
+		}
+		for _, field := range fields {
+			change.Fields = append(change.Fields, stock.FieldChange{Field: field.Field, From: field.From, To: field.To})
+		}
+		changes = append(changes, change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sync changes: %w", err)
+	}
+	return changes, nil
+}
@@ -0,0 +1,87 @@
+package cockroach
+
+import (
+	"context"
+	"fmt"
+	"stockapi/internal/domain/shared"
+	"stockapi/internal/domain/stock"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SyncStateRepository struct {
+	db     *pgxpool.Pool
+	logger shared.Logger
+}
+
+func NewSyncStateRepository(db *pgxpool.Pool, logger shared.Logger) stock.SyncStateRepository {
+	return &SyncStateRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *SyncStateRepository) FindAllSyncStates(ctx context.Context) ([]stock.SyncState, error) {
+	query := `
+        SELECT source, last_event_time, last_full_sync_at, updated_at
+        FROM sync_state
+    `
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error querying sync state: %w", err)
+	}
+	defer rows.Close()
+
+	var states []stock.SyncState
+	for rows.Next() {
+		var state stock.SyncState
+		var lastEvent, lastFull *time.Time
+		if err := rows.Scan(&state.Source, &lastEvent, &lastFull, &state.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning sync state: %w", err)
+		}
+		if lastEvent != nil {
+			state.LastEventTime = *lastEvent
+		}
+		if lastFull != nil {
+			state.LastFullSyncAt = *lastFull
+		}
+		states = append(states, state)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sync state: %w", err)
+	}
+	return states, nil
+}
+
+func (r *SyncStateRepository) SaveSyncState(ctx context.Context, state stock.SyncState) error {
+	query := `
+        UPSERT INTO sync_state (source, last_event_time, last_full_sync_at, updated_at)
+        VALUES ($1, $2, $3, now())
+    `
+
+	_, err := r.db.Exec(ctx, query,
+		state.Source,
+		nullableTime(state.LastEventTime),
+		nullableTime(state.LastFullSyncAt),
+	)
+	if err != nil {
+		return fmt.Errorf("error saving sync state: %w", err)
+	}
+
+	r.logger.Debug(ctx, "Sync state saved", map[string]interface{}{
+		"source":          state.Source,
+		"last_event_time": state.LastEventTime,
+	})
+	return nil
+}
+
+// nullableTime stores the zero time as NULL
+func nullableTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -13,6 +13,7 @@ export interface Stock {
   rating_from: string;
   rating_to: string;
   time: string;
+  source: string;
 }
 
 export interface StockIndicators {